@@ -1,12 +1,16 @@
 package controller
 
 import (
+	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/0l1v3rr/todo/app/mail"
 	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/ratelimit"
 	"github.com/0l1v3rr/todo/app/util"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -16,16 +20,17 @@
 
 // @Summary      Registration
 // @Description  Registers a new user into the database.
+// @Description  The user is disabled until the email address is verified with the link sent in an email.
+// @Description  If the email is already registered, the response is the same, and the owner of the email gets a notice instead.
 // @Tags         User endpoints
 // @Accept       json
 // @Produce      json
 // @Param 		 user body model.User false "User to register"
-// @Success      201  {object}  model.User "If the user has been created successfully."
+// @Success      201  {object}  util.Success "If the registration has been accepted."
 // @Failure      400  {object}  util.Error "If the provided user is not valid."
-// @Failure      409  {object}  util.Error "If the specified email already exists."
 // @Failure      500  {object}  util.Error "If there was a server error while creating the user."
 // @Router       /register [post]
-func Register(c *gin.Context) {
+func (h *Handler) Register(c *gin.Context) {
 	// binding the user from the body
 	var user model.User
 
@@ -41,37 +46,51 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// checking if the email is already in the db
-	exists := model.ExistsByEmail(user.Email)
-	if exists {
-		c.JSON(http.StatusConflict, util.Error{Message: "This email is already registered."})
+	// every outcome gets the same response, so the endpoint can't be used to find registered emails
+	accepted := util.Success{Message: "Please check your email to finish the registration."}
+
+	// if the email is already in the db, its owner is notified instead
+	// the password is still hashed, so the timing doesn't tell either
+	if existing, err := h.Users.GetUserByEmail(user.Email); err == nil && existing.Id != 0 {
+		bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(user.Password))
+
+		if err := mail.SendRegistrationNotice(existing.Email, existing.Name); err != nil {
+			log.Println("Failed to send the registration notice: " + err.Error())
+		}
+
+		c.JSON(http.StatusCreated, accepted)
 		return
 	}
 
 	// creating the user with the model
-	created, err := model.Register(user)
+	created, err := h.Users.Register(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
 	}
 
-	// return with json
-	created.Password = ""
-	c.JSON(http.StatusCreated, created)
+	// sending the verification email
+	// the user can request a new one if it fails, so the registration still succeeds
+	h.sendVerification(created)
+
+	c.JSON(http.StatusCreated, accepted)
 }
 
 // @Summary      Login
-// @Description  Logs in a user and saves the JWT in cookies.
+// @Description  Logs in a user and saves a short-lived JWT and a refresh token in cookies.
+// @Description  The JWT can be renewed with the refresh endpoint until the session is revoked.
 // @Tags         User endpoints
 // @Accept       json
 // @Produce      json
 // @Param 		 user body model.LoginUser false "User to log in"
 // @Success      200  {object}  util.Success "If the login was successful."
+// @Success      202  {object}  model.MfaChallenge "If the user has 2FA, and the login has to be finished with a code."
 // @Failure      400  {object}  util.Error "If the provided user is not valid."
-// @Failure      404  {object}  util.Error "If the user with the specified email does not exist."
-// @Failure      403  {object}  util.Error "If the password is incorrect or the user is not activated."
+// @Failure      401  {object}  util.Error "If the email or the password is incorrect."
+// @Failure      403  {object}  util.Error "If the user is not activated."
+// @Failure      429  {object}  util.Error "If there were too many attempts. The Retry-After header contains the seconds to wait."
 // @Router       /login [post]
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	// binding the user from the body
 	var user model.LoginUser
 
@@ -80,54 +99,266 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// if the account is locked because of the failed attempts
+	account := loginAccountKey(user.Email)
+	if wait, _ := ratelimit.Backend.LockedFor(account, time.Now()); wait > 0 {
+		ratelimit.Reject(c, wait, "Too many failed login attempts. Please try again later.")
+		return
+	}
+
+	// the attempts are also limited per account, so they can't be spread between ip addresses
+	if wait, _ := ratelimit.Backend.Take(account, accountLoginLimit, time.Now()); wait > 0 {
+		ratelimit.Reject(c, wait, "Too many login attempts. Please try again later.")
+		return
+	}
+
 	// getting the user with the given email from the db
-	foundUser, err := model.GetUserByEmail(user.Email)
+	foundUser, err := h.Users.GetUserByEmail(user.Email)
+
+	// the password is checked even if there's no user with this email,
+	// so neither the response nor its timing tells whether the email is registered
+	hash := foundUser.Password
+	if foundUser.Id == 0 || err != nil || hash == "" {
+		hash = dummyHash
+	}
 
-	// if there is no user with this email
-	if foundUser.Id == 0 || err != nil {
-		c.JSON(http.StatusNotFound, util.Error{Message: "User with this email does not exist."})
+	// if the email or the password is incorrect
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(user.Password)) != nil || hash == dummyHash {
+		failLogin(c, account)
 		return
 	}
 
 	// if the user is not enabled
 	if !foundUser.IsEnabled {
-		c.JSON(http.StatusForbidden, util.Error{Message: "This user is not activated."})
+		c.JSON(http.StatusForbidden, util.Error{Message: "This user is not activated. Please verify your email address."})
 		return
 	}
 
-	// if the password is incorrect
-	if err := bcrypt.CompareHashAndPassword([]byte(foundUser.Password), []byte(user.Password)); err != nil {
-		c.JSON(http.StatusForbidden, util.Error{Message: "Incorrect password."})
+	// users with 2fa get a token for the second step instead of a session
+	if foundUser.TotpEnabled {
+		token, err := h.Tokens.CreateToken(foundUser.Id, model.TokenMfa, mfaTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, model.MfaChallenge{MfaRequired: true, MfaToken: token})
 		return
 	}
 
+	// logging in the user
+	if err := h.startSession(c, foundUser); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+
+	// the failures are only forgotten after a complete login
+	ratelimit.Backend.Reset(account)
+
+	// successful login
+	c.JSON(http.StatusOK, util.Success{Message: "Successful login!"})
+}
+
+// @Summary      Refresh
+// @Description  Issues a new JWT with the refresh token saved in the cookies.
+// @Description  The refresh token is replaced by a new one, and reusing an old refresh token revokes the session.
+// @Tags         User endpoints
+// @Produce      json
+// @Success      200  {object}  util.Success "If the tokens have been refreshed."
+// @Failure      401  {object}  util.Error "If the refresh token is missing, expired or revoked."
+// @Failure      500  {object}  util.Error "If there was a server error while refreshing the tokens."
+// @Router       /refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	// getting the refresh token from the cookies
+	refresh, err := c.Cookie("refresh")
+	if err != nil || refresh == "" {
+		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in!"})
+		return
+	}
+
+	// rotating the refresh token
+	session, newRefresh, err := h.Sessions.RefreshSession(refresh, c.ClientIP(), refreshTTL)
+	if err == model.ErrSessionNotFound {
+		clearSessionCookies(c)
+		c.JSON(http.StatusUnauthorized, util.Error{Message: "Your session has expired. Please log in again."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to refresh the session."})
+		return
+	}
+
+	if err := setSessionCookies(c, session, newRefresh); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to refresh the session."})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "Successful refresh!"})
+}
+
+// counts a failed login of the account, and locks it after too many of them
+func failLogin(c *gin.Context, account string) {
+	wait, err := ratelimit.Backend.Fail(account, loginLockout, time.Now())
+	if err == nil && wait > 0 {
+		ratelimit.Reject(c, wait, "Too many failed login attempts. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, util.Error{Message: "Incorrect email or password."})
+}
+
+// the key of the lockout of an account, which also works for the emails that are not registered
+func loginAccountKey(email string) string {
+	return "login:account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// creates a new session for the user and saves its tokens in the cookies
+func (h *Handler) startSession(c *gin.Context, user model.User) error {
+	session, refresh, err := h.Sessions.CreateSession(user.Id, c.Request.UserAgent(), c.ClientIP(), refreshTTL)
+	if err != nil {
+		return err
+	}
+
+	return setSessionCookies(c, session, refresh)
+}
+
+func setSessionCookies(c *gin.Context, session model.Session, refresh string) error {
 	// creating the jwt claims
-	// it expires in 30 days
+	// the id of the token is the id of the session
 	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		Issuer:    strconv.Itoa(foundUser.Id),
-		ExpiresAt: time.Now().Add((time.Hour * 24) * 30).Unix(), // 30 days
+		Id:        strconv.Itoa(session.Id),
+		Issuer:    strconv.Itoa(session.UserId),
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(accessTTL).Unix(),
 	})
 
 	// creating the token from the claims
 	token, err := claims.SignedString([]byte(os.Getenv("JWT_SECRET")))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
-		return
+		return err
 	}
 
 	// creating the cookie for the token
 	c.SetCookie(
 		"jwt",
 		token,
-		3600*24*30, // 30 days
+		int(accessTTL.Seconds()),
 		"/",
 		"localhost",
 		false,
 		true,
 	)
 
-	// successful login
-	c.JSON(http.StatusOK, util.Success{Message: "Successful login!"})
+	// the refresh token is only sent to the api
+	c.SetCookie(
+		"refresh",
+		refresh,
+		int(time.Until(session.ExpiresAt).Seconds()),
+		"/api/v1",
+		"localhost",
+		false,
+		true,
+	)
+
+	return nil
+}
+
+func clearSessionCookies(c *gin.Context) {
+	c.SetCookie("jwt", "", -3600, "/", "localhost", false, true)
+	c.SetCookie("refresh", "", -3600, "/api/v1", "localhost", false, true)
+}
+
+// @Summary      Forgot password
+// @Description  Sends a password reset link to the user with the given email address.
+// @Description  The response is the same whether or not the email address is registered.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        email body model.EmailRequest true "The email address of the account"
+// @Success      200  {object}  util.Success "If the request has been accepted."
+// @Failure      400  {object}  util.Error "If the provided email is not valid."
+// @Router       /password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	// binding the email from the body
+	var body model.EmailRequest
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid email address."})
+		return
+	}
+
+	// every outcome gets the same response, so the endpoint can't be used to find registered emails
+	accepted := util.Success{Message: "If this email address is registered, we have sent a password reset link."}
+
+	user, err := h.Users.GetUserByEmail(body.Email)
+	if err != nil || user.Id == 0 {
+		c.JSON(http.StatusOK, accepted)
+		return
+	}
+
+	// if a link has been sent recently, no new email is sent
+	if last, ok := h.Tokens.LastTokenCreatedAt(user.Id, model.TokenReset); ok && time.Since(last) < resendCooldown {
+		c.JSON(http.StatusOK, accepted)
+		return
+	}
+
+	// creating the token and sending the email
+	token, err := h.Tokens.CreateToken(user.Id, model.TokenReset, resetTTL)
+	if err == nil {
+		err = mail.SendPasswordReset(user.Email, user.Name, token)
+	}
+	if err != nil {
+		log.Println("Failed to send the password reset email: " + err.Error())
+	}
+
+	c.JSON(http.StatusOK, accepted)
+}
+
+// @Summary      Reset password
+// @Description  Sets a new password with the token sent in the password reset email.
+// @Description  Every existing login and personal access token of the user is invalidated.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        reset body model.ResetPassword true "The token and the new password"
+// @Success      200  {object}  util.Success "If the password has been changed."
+// @Failure      400  {object}  util.Error "If the password is not valid, or the token is invalid, expired or already used."
+// @Failure      500  {object}  util.Error "If there was a server error while changing the password."
+// @Router       /password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	// binding the token and the password from the body
+	var body model.ResetPassword
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil || body.Token == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid token and password."})
+		return
+	}
+
+	// validating the password before the token is used
+	ok, msg := model.ValidatePassword(body.Password)
+	if !ok {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// using the token
+	token, err := h.Tokens.UseToken(body.Token, model.TokenReset)
+	if err == model.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "This password reset link is invalid or has expired."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// changing the password, which also logs out every session and revokes the access tokens
+	if err := h.Users.ResetPassword(token.UserId, body.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to change the password."})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "Your password has been changed. Please log in again."})
 }
 
 // @Summary      Logged In User
@@ -137,37 +368,29 @@ func Login(c *gin.Context) {
 // @Success      200  {object}  model.User "If the user is logged in."
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Router       /user [get]
-func GetLoggedInUser(c *gin.Context) {
+func (h *Handler) GetLoggedInUser(c *gin.Context) {
 	// getting the logged in user
-	user, err := model.GetLoggedInUser(*c)
-
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in!"})
-		return
-	}
-
-	// if the user is logged in
+	user := loggedInUser(c)
 	user.Password = ""
 	c.JSON(http.StatusOK, user)
 }
 
 // @Summary      Logout
-// @Description  Logs out the currently logged-in user.
+// @Description  Logs out the currently logged-in user and revokes the session.
 // @Tags         User endpoints
 // @Produce      json
 // @Success      200  {object}  util.Success "If the logout was success."
 // @Router       /logout [post]
-func Logout(c *gin.Context) {
-	// removing the cookie
-	c.SetCookie(
-		"jwt",
-		"",
-		-3600,
-		"/",
-		"localhost",
-		false,
-		true,
-	)
+func (h *Handler) Logout(c *gin.Context) {
+	// revoking the session, with the refresh token if the jwt has already expired
+	if session, err := h.Sessions.GetLoggedInSession(c); err == nil {
+		h.Sessions.RevokeSession(session.UserId, session.Id)
+	} else if refresh, err := c.Cookie("refresh"); err == nil {
+		h.Sessions.RevokeRefreshToken(refresh)
+	}
+
+	// removing the cookies
+	clearSessionCookies(c)
 
 	// json response
 	c.JSON(http.StatusOK, util.Success{Message: "Successful logout!"})
@@ -0,0 +1,756 @@
+package controller
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+var errNotFound = errors.New("record not found")
+
+// an in-memory store for the handler tests, it implements the list, the task and the tag stores
+// the other stores are embedded and only the methods the handlers need are implemented, so calling the rest panics
+type fakeStore struct {
+	model.UserStore
+	model.SessionStore
+	model.AccessTokenStore
+
+	users        map[int]model.User
+	sessions     map[string]model.Session
+	accessTokens map[string]model.AccessToken
+	lists        map[int]model.List
+	members      map[int]model.Member
+	tasks        map[int]model.Task
+	tags         map[int]model.Tag
+	taskTags     map[int][]int
+	lastId       int
+}
+
+// making sure the fake implements every method of the stores it fakes
+var (
+	_ model.ListStore = (*fakeStore)(nil)
+	_ model.TaskStore = (*fakeStore)(nil)
+	_ model.TagStore  = (*fakeStore)(nil)
+)
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:        map[int]model.User{},
+		sessions:     map[string]model.Session{},
+		accessTokens: map[string]model.AccessToken{},
+		lists:        map[int]model.List{},
+		members:      map[int]model.Member{},
+		tasks:        map[int]model.Task{},
+		tags:         map[int]model.Tag{},
+		taskTags:     map[int][]int{},
+	}
+}
+
+// returns a handler that reaches the fake instead of the db
+func (s *fakeStore) handler() *Handler {
+	return &Handler{Users: s, Sessions: s, AccessTokens: s, Lists: s, Tasks: s, Tags: s}
+}
+
+func (s *fakeStore) nextId() int {
+	s.lastId++
+	return s.lastId
+}
+
+// adds a user with a session, the session cookie of the requests is the returned value
+func (s *fakeStore) login(name string) (model.User, string) {
+	user := model.User{Id: s.nextId(), Name: name, Email: name + "@example.com", IsEnabled: true}
+	s.users[user.Id] = user
+
+	cookie := "session-" + name
+	s.sessions[cookie] = model.Session{Id: s.nextId(), UserId: user.Id}
+
+	return user, cookie
+}
+
+func (s *fakeStore) GetUserById(id int) (model.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return model.User{}, errNotFound
+	}
+
+	return user, nil
+}
+
+func (s *fakeStore) GetUserByEmail(email string) (model.User, error) {
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return model.User{}, errNotFound
+}
+
+func (s *fakeStore) GetLoggedInSession(c *gin.Context) (model.Session, error) {
+	cookie, err := c.Cookie("jwt")
+	if err != nil {
+		return model.Session{}, err
+	}
+
+	session, ok := s.sessions[cookie]
+	if !ok {
+		return model.Session{}, model.ErrTokenRevoked
+	}
+
+	return session, nil
+}
+
+func (s *fakeStore) AuthenticateAccessToken(raw string) (model.AccessToken, error) {
+	token, ok := s.accessTokens[raw]
+	if !ok {
+		return model.AccessToken{}, model.ErrInvalidAccessToken
+	}
+
+	return token, nil
+}
+
+func (s *fakeStore) GetTags(ownerId int) ([]model.Tag, error) {
+	tags := []model.Tag{}
+	for _, tag := range s.tags {
+		if tag.OwnerId == ownerId {
+			tags = append(tags, tag)
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
+
+func (s *fakeStore) GetTagById(id int) (model.Tag, error) {
+	tag, ok := s.tags[id]
+	if !ok {
+		return model.Tag{}, errNotFound
+	}
+
+	return tag, nil
+}
+
+func (s *fakeStore) TagNameExists(ownerId int, name string, exceptId int) bool {
+	for _, tag := range s.tags {
+		if tag.OwnerId == ownerId && tag.Name == name && tag.Id != exceptId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *fakeStore) OwnsTags(ownerId int, tags []model.Tag) bool {
+	for _, tag := range tags {
+		if s.tags[tag.Id].OwnerId != ownerId {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *fakeStore) CreateTag(tag model.Tag) (model.Tag, error) {
+	tag.Id = s.nextId()
+	s.tags[tag.Id] = tag
+	return tag, nil
+}
+
+func (s *fakeStore) EditTag(tag model.Tag) (model.Tag, error) {
+	s.tags[tag.Id] = tag
+	return tag, nil
+}
+
+func (s *fakeStore) DeleteTag(id int) error {
+	delete(s.tags, id)
+	return nil
+}
+
+func (s *fakeStore) MergeTag(fromId int, intoId int) error {
+	// the tasks of the merged tag get the other tag once
+	for taskId, tagIds := range s.taskTags {
+		merged := []int{}
+		hasInto := false
+		for _, tagId := range tagIds {
+			hasInto = hasInto || tagId == intoId
+		}
+		for _, tagId := range tagIds {
+			if tagId == fromId && !hasInto {
+				merged = append(merged, intoId)
+				hasInto = true
+			} else if tagId != fromId {
+				merged = append(merged, tagId)
+			}
+		}
+		s.taskTags[taskId] = merged
+	}
+
+	delete(s.tags, fromId)
+	return nil
+}
+
+// the rank of each role, the same as in the model
+var fakeRoleRanks = map[string]int{
+	model.RoleViewer: 1,
+	model.RoleEditor: 2,
+	model.RoleOwner:  3,
+}
+
+// returns the page of the rows, the cursors of the fake are the offsets of the pages
+func fakePage(count int, page model.Page) (int, int, model.PageInfo, error) {
+	from := 0
+	if page.Cursor != "" {
+		offset, err := strconv.Atoi(page.Cursor)
+		if err != nil || offset < 0 || offset > count {
+			return 0, 0, model.PageInfo{}, model.ErrInvalidCursor
+		}
+		from = offset
+	}
+
+	if page.Limit <= 0 {
+		return from, count, model.PageInfo{}, nil
+	}
+
+	var info model.PageInfo
+	to := from + page.Limit
+	if to < count {
+		info.Next = strconv.Itoa(to)
+	} else {
+		to = count
+	}
+	if from > 0 {
+		prev := from - page.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		info.Prev = strconv.Itoa(prev)
+	}
+
+	return from, to, info, nil
+}
+
+func (s *fakeStore) GetRole(listId int, userId int) string {
+	list, ok := s.lists[listId]
+	if !ok {
+		return ""
+	}
+	if list.OwnerId == userId {
+		return model.RoleOwner
+	}
+
+	for _, member := range s.members {
+		if member.ListId == listId && member.UserId == userId {
+			return member.Role
+		}
+	}
+
+	return ""
+}
+
+// returns the lists in the sidebar of the user with the position of the user
+func (s *fakeStore) sidebar(userId int) []model.List {
+	lists := []model.List{}
+	for _, list := range s.lists {
+		if list.OwnerId == userId {
+			lists = append(lists, list)
+		}
+	}
+	for _, member := range s.members {
+		if list, ok := s.lists[member.ListId]; ok && member.UserId == userId {
+			list.Position = member.Position
+			lists = append(lists, list)
+		}
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		if lists[i].Position != lists[j].Position {
+			return lists[i].Position < lists[j].Position
+		}
+		return lists[i].Id < lists[j].Id
+	})
+
+	return lists
+}
+
+// saves the position of the list in the sidebar of the user
+func (s *fakeStore) setSidebarPosition(userId int, listId int, position float64) {
+	if list := s.lists[listId]; list.OwnerId == userId {
+		list.Position = position
+		s.lists[listId] = list
+		return
+	}
+
+	for id, member := range s.members {
+		if member.ListId == listId && member.UserId == userId {
+			member.Position = position
+			s.members[id] = member
+		}
+	}
+}
+
+// returns the position before the first list of the sidebar of the user
+func (s *fakeStore) firstListPosition(userId int) float64 {
+	if lists := s.sidebar(userId); len(lists) > 0 {
+		return lists[0].Position - 1
+	}
+
+	return 0
+}
+
+func (s *fakeStore) GetLists(userId int, filter model.ListFilter, sortBy string, page model.Page) ([]model.List, model.PageInfo, error) {
+	lists := []model.List{}
+	for _, list := range s.sidebar(userId) {
+		if strings.Contains(strings.ToLower(list.Name), strings.ToLower(filter.Name)) {
+			lists = append(lists, list)
+		}
+	}
+
+	switch sortBy {
+	case "name":
+		sort.SliceStable(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	case "created":
+		sort.SliceStable(lists, func(i, j int) bool { return lists[i].Id < lists[j].Id })
+	}
+
+	from, to, info, err := fakePage(len(lists), page)
+	if err != nil {
+		return []model.List{}, model.PageInfo{}, err
+	}
+
+	return lists[from:to], info, nil
+}
+
+func (s *fakeStore) GetListByUrl(url string) (model.List, error) {
+	for _, list := range s.lists {
+		if list.Url == url {
+			return list, nil
+		}
+	}
+
+	return model.List{}, errNotFound
+}
+
+func (s *fakeStore) ListExists(id int) (model.List, bool) {
+	list, ok := s.lists[id]
+	return list, ok
+}
+
+func (s *fakeStore) CreateList(list model.List) (model.List, error) {
+	list.Id = s.nextId()
+	list.Url = "list-" + strconv.Itoa(list.Id)
+	list.ImageUrl = ""
+	list.Position = s.firstListPosition(list.OwnerId)
+	s.lists[list.Id] = list
+	return list, nil
+}
+
+func (s *fakeStore) EditList(list model.List) (model.List, error) {
+	s.lists[list.Id] = list
+	return list, nil
+}
+
+func (s *fakeStore) MoveList(userId int, id int, targetId int, after bool) error {
+	// putting the list next to the target and numbering the sidebar again
+	ids := []int{}
+	found := false
+	for _, list := range s.sidebar(userId) {
+		if list.Id != id {
+			ids = append(ids, list.Id)
+		}
+		if list.Id == targetId && targetId != id {
+			found = true
+		}
+	}
+	if !found {
+		return model.ErrTargetNotFound
+	}
+
+	for i, listId := range insertNextTo(ids, id, targetId, after) {
+		s.setSidebarPosition(userId, listId, float64(i+1))
+	}
+
+	return nil
+}
+
+func (s *fakeStore) DeleteList(id int) error {
+	delete(s.lists, id)
+	for taskId, task := range s.tasks {
+		if task.ListId == id {
+			delete(s.tasks, taskId)
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStore) HasRole(listId int, userId int, role string) bool {
+	return fakeRoleRanks[s.GetRole(listId, userId)] >= fakeRoleRanks[role]
+}
+
+func (s *fakeStore) GetMembers(listId int) ([]model.Member, error) {
+	members := []model.Member{}
+	for _, member := range s.members {
+		if member.ListId == listId {
+			member.Name = s.users[member.UserId].Name
+			member.Email = s.users[member.UserId].Email
+			members = append(members, member)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].Id < members[j].Id })
+	return members, nil
+}
+
+func (s *fakeStore) GetMemberById(id int) (model.Member, error) {
+	member, ok := s.members[id]
+	if !ok {
+		return model.Member{}, errNotFound
+	}
+
+	return member, nil
+}
+
+func (s *fakeStore) IsMember(listId int, userId int) bool {
+	for _, member := range s.members {
+		if member.ListId == listId && member.UserId == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *fakeStore) CreateMember(member model.Member) (model.Member, error) {
+	member.Id = s.nextId()
+	member.Position = s.firstListPosition(member.UserId)
+	s.members[member.Id] = member
+	return member, nil
+}
+
+func (s *fakeStore) EditMember(member model.Member) (model.Member, error) {
+	s.members[member.Id] = member
+	return member, nil
+}
+
+func (s *fakeStore) DeleteMember(id int) error {
+	delete(s.members, id)
+	return nil
+}
+
+// returns the ids with the id put right before or after the target
+func insertNextTo(ids []int, id int, targetId int, after bool) []int {
+	ordered := []int{}
+	for _, other := range ids {
+		if other == targetId && !after {
+			ordered = append(ordered, id)
+		}
+		ordered = append(ordered, other)
+		if other == targetId && after {
+			ordered = append(ordered, id)
+		}
+	}
+
+	return ordered
+}
+
+// returns the task with the tags of the user
+func (s *fakeStore) withTags(task model.Task, userId int) model.Task {
+	task.Tags = []model.Tag{}
+	for _, tagId := range s.taskTags[task.Id] {
+		if tag, ok := s.tags[tagId]; ok && tag.OwnerId == userId {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+
+	return task
+}
+
+// returns the tasks of the lists the user can view that match, with the tags of the user
+func (s *fakeStore) accessibleTasks(userId int, match func(task model.Task) bool) []model.Task {
+	tasks := []model.Task{}
+	for _, task := range s.tasks {
+		if s.HasRole(task.ListId, userId, model.RoleViewer) && match(task) {
+			tasks = append(tasks, s.withTags(task, userId))
+		}
+	}
+
+	return tasks
+}
+
+// orders the tasks like the sorts of the model
+func sortTasks(tasks []model.Task, sortBy string) {
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+
+		switch sortBy {
+		case "position":
+			if a.Position != b.Position {
+				return a.Position < b.Position
+			}
+		case "priority":
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			fallthrough
+		case "due":
+			if (a.DueDate == nil) != (b.DueDate == nil) {
+				return b.DueDate == nil
+			}
+			if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+				return a.DueDate.Before(*b.DueDate)
+			}
+		}
+
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.Id < b.Id
+	})
+}
+
+// returns the position before the first task of the list
+func (s *fakeStore) firstTaskPosition(listId int) float64 {
+	first := 0.0
+	for _, task := range s.tasks {
+		if task.ListId == listId && task.Position-1 < first {
+			first = task.Position - 1
+		}
+	}
+
+	return first
+}
+
+func (s *fakeStore) GetTasks(listId int, userId int, filter model.TaskFilter, sortBy string, page model.Page) ([]model.Task, model.PageInfo, error) {
+	tasks := s.accessibleTasks(userId, func(task model.Task) bool {
+		return task.ListId == listId &&
+			(filter.IsDone == nil || task.IsDone == *filter.IsDone) &&
+			(filter.CreatedAfter == nil || !task.CreatedAt.Before(*filter.CreatedAfter)) &&
+			(filter.CreatedBefore == nil || task.CreatedAt.Before(*filter.CreatedBefore)) &&
+			strings.Contains(strings.ToLower(task.Title), strings.ToLower(filter.Title))
+	})
+	sortTasks(tasks, sortBy)
+
+	from, to, info, err := fakePage(len(tasks), page)
+	if err != nil {
+		return []model.Task{}, model.PageInfo{}, err
+	}
+
+	return tasks[from:to], info, nil
+}
+
+func (s *fakeStore) GetTopPriorityTasks(userId int, limit int) ([]model.Task, error) {
+	tasks := s.accessibleTasks(userId, func(task model.Task) bool {
+		return !task.IsDone && task.Priority > model.PriorityNone
+	})
+	sortTasks(tasks, "priority")
+
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	return tasks, nil
+}
+
+func (s *fakeStore) GetOverdueTasks(userId int, now time.Time) ([]model.Task, error) {
+	tasks := s.accessibleTasks(userId, func(task model.Task) bool {
+		return !task.IsDone && task.DueDate != nil && task.DueDate.Before(now)
+	})
+	sortTasks(tasks, "due")
+	return tasks, nil
+}
+
+func (s *fakeStore) GetTasksDueBetween(userId int, from time.Time, to time.Time) ([]model.Task, error) {
+	tasks := s.accessibleTasks(userId, func(task model.Task) bool {
+		return !task.IsDone && task.DueDate != nil && !task.DueDate.Before(from) && task.DueDate.Before(to)
+	})
+	sortTasks(tasks, "due")
+	return tasks, nil
+}
+
+func (s *fakeStore) GetTaskByUrl(url string, userId int) (model.Task, error) {
+	for _, task := range s.tasks {
+		if task.Url == url {
+			return s.withTags(task, userId), nil
+		}
+	}
+
+	return model.Task{}, errNotFound
+}
+
+func (s *fakeStore) TaskExists(id int) (model.Task, bool) {
+	task, ok := s.tasks[id]
+	return task, ok
+}
+
+func (s *fakeStore) CreateTask(task model.Task) (model.Task, error) {
+	task.Id = s.nextId()
+	task.Url = "task-" + strconv.Itoa(task.Id)
+	task.CreatedAt = time.Now()
+	task.Position = s.firstTaskPosition(task.ListId)
+	return task, s.saveTask(task)
+}
+
+func (s *fakeStore) EditTask(task model.Task) (model.Task, error) {
+	return task, s.saveTask(task)
+}
+
+// saves the task without its tags, they are linked separately like in the db
+func (s *fakeStore) saveTask(task model.Task) error {
+	task.Tags = nil
+	s.tasks[task.Id] = task
+	return nil
+}
+
+// the fake has no checklists and doesn't repeat the recurring tasks
+func (s *fakeStore) ChangeIsDone(id int, completeItems bool, userId int, loc *time.Location) (model.Task, error) {
+	task, ok := s.tasks[id]
+	if !ok {
+		return model.Task{}, errNotFound
+	}
+
+	task.IsDone = !task.IsDone
+	s.tasks[id] = task
+	return s.withTags(task, userId), nil
+}
+
+func (s *fakeStore) MoveTask(id int, targetId int, after bool) (model.Task, error) {
+	task := s.tasks[id]
+	target, ok := s.tasks[targetId]
+	if !ok || targetId == id || target.ListId != task.ListId {
+		return model.Task{}, model.ErrTargetNotFound
+	}
+
+	// putting the task next to the target and numbering the list again
+	tasks := []model.Task{}
+	for _, other := range s.tasks {
+		if other.ListId == task.ListId && other.Id != id {
+			tasks = append(tasks, other)
+		}
+	}
+	sortTasks(tasks, "position")
+
+	ids := []int{}
+	for _, other := range tasks {
+		ids = append(ids, other.Id)
+	}
+
+	for i, taskId := range insertNextTo(ids, id, targetId, after) {
+		other := s.tasks[taskId]
+		other.Position = float64(i + 1)
+		s.tasks[taskId] = other
+	}
+
+	return s.tasks[id], nil
+}
+
+func (s *fakeStore) MoveTasks(ids []int, listId int, regenerateUrl bool, userId int) ([]model.Task, error) {
+	first := s.firstTaskPosition(listId)
+
+	moved := []model.Task{}
+	for i, id := range ids {
+		task := s.tasks[id]
+		task.ListId = listId
+		task.Position = first - float64(len(ids)-1-i)
+		if regenerateUrl {
+			task.Url = "task-" + strconv.Itoa(s.nextId())
+		}
+
+		s.tasks[id] = task
+
+		// the users who can't access the new list lose their tags
+		tagIds := []int{}
+		for _, tagId := range s.taskTags[id] {
+			if s.GetRole(listId, s.tags[tagId].OwnerId) != "" {
+				tagIds = append(tagIds, tagId)
+			}
+		}
+		s.taskTags[id] = tagIds
+
+		moved = append(moved, s.withTags(task, userId))
+	}
+
+	return moved, nil
+}
+
+func (s *fakeStore) CopyTasks(ids []int, listId int, userId int) ([]model.Task, error) {
+	first := s.firstTaskPosition(listId)
+
+	copies := []model.Task{}
+	for i, id := range ids {
+		task := s.tasks[id]
+		task.Id = s.nextId()
+		task.ListId = listId
+		task.CreatedById = &userId
+		task.Url = "task-" + strconv.Itoa(task.Id)
+		task.CreatedAt = time.Now()
+		task.Position = first - float64(len(ids)-1-i)
+		s.tasks[task.Id] = task
+
+		// only the tags of the user are copied
+		for _, tagId := range s.taskTags[id] {
+			if s.tags[tagId].OwnerId == userId {
+				s.taskTags[task.Id] = append(s.taskTags[task.Id], tagId)
+			}
+		}
+
+		copies = append(copies, s.withTags(task, userId))
+	}
+
+	return copies, nil
+}
+
+func (s *fakeStore) DeleteTask(id int) error {
+	delete(s.tasks, id)
+	return nil
+}
+
+func (s *fakeStore) SetTaskTags(taskId int, ownerId int, tags []model.Tag) error {
+	// keeping the tags of the other users
+	ids := []int{}
+	for _, tagId := range s.taskTags[taskId] {
+		if s.tags[tagId].OwnerId != ownerId {
+			ids = append(ids, tagId)
+		}
+	}
+	for _, tag := range tags {
+		ids = append(ids, tag.Id)
+	}
+
+	s.taskTags[taskId] = ids
+	return nil
+}
+
+func (s *fakeStore) GetTaskTags(taskId int, userId int) ([]model.Tag, error) {
+	return s.withTags(s.tasks[taskId], userId).Tags, nil
+}
+
+func (s *fakeStore) GetTasksByTags(userId int, names []string) ([]model.Task, error) {
+	tasks := s.accessibleTasks(userId, func(task model.Task) bool { return true })
+
+	matching := []model.Task{}
+	for _, task := range tasks {
+		hasAll := true
+		for _, name := range names {
+			found := false
+			for _, tag := range task.Tags {
+				found = found || tag.Name == name
+			}
+			hasAll = hasAll && found
+		}
+
+		if hasAll {
+			matching = append(matching, task)
+		}
+	}
+
+	sortTasks(matching, "created")
+	return matching, nil
+}
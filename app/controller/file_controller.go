@@ -6,7 +6,6 @@
 	"net/http"
 	"os"
 
-	"github.com/0l1v3rr/todo/app/model"
 	"github.com/0l1v3rr/todo/app/util"
 	"github.com/gin-gonic/gin"
 )
@@ -20,14 +19,7 @@
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      500  {object}  util.Error "If there was a file error."
 // @Router       /files [post]
-func UploadFile(c *gin.Context) {
-	// checking whether the user is logged in
-	_, err := model.GetLoggedInUser(*c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
-		return
-	}
-
+func (h *Handler) UploadFile(c *gin.Context) {
 	// getting the file from the request
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
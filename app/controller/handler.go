@@ -0,0 +1,19 @@
+package controller
+
+import "github.com/0l1v3rr/todo/app/model"
+
+// the dependencies of the handlers
+// main creates it with the gorm stores, and the tests can use fakes instead
+type Handler struct {
+	Users model.UserStore
+	Lists model.ListStore
+	Tasks model.TaskStore
+	Trash model.TrashStore
+
+	Sessions     model.SessionStore
+	Tokens       model.TokenStore
+	AccessTokens model.AccessTokenStore
+	Tags         model.TagStore
+	Items        model.ItemStore
+	Searcher     model.Searcher
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+// returns the routes of main on a new in-memory sqlite db, migrated by model.Setup
+func newIntegrationRouter(t *testing.T) (*gin.Engine, *model.GormStore) {
+	t.Helper()
+
+	// every test gets its own db, which is shared by the connections of the pool
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", "file:controller_"+strings.ReplaceAll(t.Name(), "/", "_")+"?mode=memory&cache=shared")
+	t.Setenv("JWT_SECRET", "integration-secret")
+	t.Setenv("FRONTEND", "http://frontend.test")
+
+	if err := model.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := model.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := model.NewGormStore(model.DB)
+	h := &Handler{
+		Users:        store,
+		Lists:        store,
+		Tasks:        store,
+		Trash:        store,
+		Sessions:     store,
+		Tokens:       store,
+		AccessTokens: store,
+		Tags:         store,
+		Items:        store,
+		Searcher:     store,
+	}
+
+	r := gin.New()
+	public := r.Group("/api/v1")
+	public.GET("/oidc/:provider/login", h.OidcLogin)
+	public.GET("/oidc/:provider/callback", h.OidcCallback)
+
+	api := r.Group("/api/v1", h.Authenticate)
+	api.GET("/user", h.GetLoggedInUser)
+	api.PUT("/user", h.EditProfile)
+	api.GET("/tasks/list/:listId", h.GetTasksByListId)
+	api.DELETE("/tasks/:id", h.DeleteTask)
+	api.DELETE("/lists/:id", h.DeleteList)
+	api.GET("/trash", h.GetTrash)
+	api.POST("/trash/tasks/:id/restore", h.RestoreTask)
+	api.DELETE("/trash/tasks/:id", h.PurgeTask)
+	api.POST("/trash/lists/:id/restore", h.RestoreList)
+	api.DELETE("/trash/lists/:id", h.PurgeList)
+
+	return r, store
+}
+
+// creates an enabled user with a session, and returns the value of its jwt cookie
+func loginTestUser(t *testing.T, store *model.GormStore, name string) (model.User, string) {
+	t.Helper()
+
+	user := model.User{Name: name, Email: strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com", IsEnabled: true}
+	if err := model.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	session, refresh, err := store.CreateSession(user.Id, "test", "127.0.0.1", refreshTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user, sessionCookie(t, session, refresh)
+}
+
+// returns the jwt cookie the api sets for the session
+func sessionCookie(t *testing.T, session model.Session, refresh string) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if err := setSessionCookies(c, session, refresh); err != nil {
+		t.Fatal(err)
+	}
+
+	return cookieValue(w, "jwt")
+}
+
+func cookieValue(w *httptest.ResponseRecorder, name string) string {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+func TestSessionOnSqlite(t *testing.T) {
+	r, store := newIntegrationRouter(t)
+	user, cookie := loginTestUser(t, store, "Sqlite User")
+
+	w := serve(r, "GET", "/api/v1/user", "", cookie)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/user = %d %s", w.Code, w.Body)
+	}
+
+	var got model.User
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != user.Id || got.Email != user.Email {
+		t.Errorf("user = %+v, want %+v", got, user)
+	}
+
+	if w := serve(r, "GET", "/api/v1/user", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("without a session: status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// @Summary      Get checklist items
+// @Description  Returns the checklist items of the task with the specified url
+// @Tags         Item endpoints
+// @Produce      json
+// @Param 		 url path string true "task URL"
+// @Success      200  {array}   model.Item
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list the task is in."
+// @Failure      404  {object}  util.Error "If the task doesn't exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/{url}/items [get]
+func (h *Handler) GetItems(c *gin.Context) {
+	// getting the task if the user can view the list it is in
+	task, ok := h.authorizeTaskUrl(c, c.Param("url"), model.RoleViewer)
+	if !ok {
+		return
+	}
+
+	// getting the items from the db
+	items, err := h.Items.GetItems(task.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary      Create checklist item
+// @Description  Adds a new checklist item to the end of the checklist of the task
+// @Tags         Item endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 item body model.Item true "Item to create"
+// @Param 		 id path int true "task ID"
+// @Success      201  {object}  model.Item
+// @Failure      400  {object}  util.Error "If the item or the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the task doesn't exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/{id}/items [post]
+func (h *Handler) CreateItem(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// binding the item from the body
+	var item model.Item
+
+	if err := c.ShouldBindBodyWith(&item, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid item."})
+		return
+	}
+
+	// validating the item
+	valid, msg := item.Validate()
+	if !valid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// checking if the task exists and the user can edit the list it is in
+	task, ok := h.authorizeTask(c, id, model.RoleEditor)
+	if !ok {
+		return
+	}
+
+	// creating the item
+	item.TaskId = task.Id
+	created, err := h.Items.CreateItem(item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// @Summary      Edit checklist item
+// @Description  Edits the title, the status or the position of the checklist item
+// @Tags         Item endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 item body model.Item true "Item to edit"
+// @Param 		 id path int true "task ID"
+// @Param 		 itemId path int true "item ID"
+// @Success      202  {object}  model.Item
+// @Failure      400  {object}  util.Error "If the item or the ids are not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the task or the item doesn't exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/{id}/items/{itemId} [put]
+func (h *Handler) EditItem(c *gin.Context) {
+	// getting the item the user is allowed to edit
+	existingItem, ok := h.getEditableItem(c)
+	if !ok {
+		return
+	}
+
+	// binding the item from the body
+	var item model.Item
+
+	if err := c.ShouldBindBodyWith(&item, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid item."})
+		return
+	}
+
+	// validating the item
+	valid, msg := item.Validate()
+	if !valid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// keeping the id and the task of the item
+	item.Id = existingItem.Id
+	item.TaskId = existingItem.TaskId
+
+	// saving the item in the db
+	saved, err := h.Items.EditItem(item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, saved)
+}
+
+// @Summary      Delete checklist item
+// @Description  Deletes the checklist item
+// @Tags         Item endpoints
+// @Param 		 id path int true "task ID"
+// @Param 		 itemId path int true "item ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the ids are not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the task or the item doesn't exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/{id}/items/{itemId} [delete]
+func (h *Handler) DeleteItem(c *gin.Context) {
+	// getting the item the user is allowed to delete
+	item, ok := h.getEditableItem(c)
+	if !ok {
+		return
+	}
+
+	// deleting the item
+	err := h.Items.DeleteItem(item.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// returns the item from the path if it exists and the user can edit it,
+// otherwise it writes the error response and returns false
+func (h *Handler) getEditableItem(c *gin.Context) (model.Item, bool) {
+	// parsing the id parameters
+	taskId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return model.Item{}, false
+	}
+
+	itemId, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid item id."})
+		return model.Item{}, false
+	}
+
+	// checking if the task exists and the user can edit the list it is in
+	task, ok := h.authorizeTask(c, taskId, model.RoleEditor)
+	if !ok {
+		return model.Item{}, false
+	}
+
+	// checking if the item exists in the task
+	item, err := h.Items.GetItemById(itemId)
+	if err != nil || item.TaskId != task.Id {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Item with this ID does not exist."})
+		return model.Item{}, false
+	}
+
+	return item, true
+}
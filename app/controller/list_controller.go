@@ -11,17 +11,23 @@
 )
 
 // @Summary      Get lists
-// @Description  Returns all the lists the specified user has
+// @Description  Returns all the lists the specified user owns or is a member of, a page at a time if a limit is specified.
+// @Description  The urls of the next and the previous pages are in the Link header.
 // @Tags         List endpoints
 // @Produce      json
 // @Param 		 userId path int true "user ID"
+// @Param 		 sort query string false "the order of the lists" Enums(position, name, created) default(position)
+// @Param 		 limit query int false "the maximum number of lists" minimum(1) maximum(100)
+// @Param 		 cursor query string false "the cursor of the page from the Link header"
+// @Param 		 name query string false "only the lists with a name containing this text"
 // @Success      200  {array}   model.List
-// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Header       200  {string}  Link "the urls of the next and the previous pages"
+// @Failure      400  {object}  util.Error "If the id, the sort or the page is not valid."
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list."
 // @Failure      500  {object}  util.Error "If there was a db error."
 // @Router       /lists/user/{userId} [get]
-func GetListsByUserId(c *gin.Context) {
+func (h *Handler) GetListsByUserId(c *gin.Context) {
 	// parsing the userId parameter
 	userId, err := strconv.Atoi(c.Param("userId"))
 	if err != nil {
@@ -29,26 +35,37 @@ func GetListsByUserId(c *gin.Context) {
 		return
 	}
 
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+	// checking whether the sort is valid
+	sort := c.DefaultQuery("sort", "position")
+	if !model.IsValidListSort(sort) {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid sort."})
 		return
 	}
 
-	// checking if the user has permission to view the list
-	if user.Id != userId {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to view this list."})
+	// parsing the page
+	page, ok := bindPage(c)
+	if !ok {
 		return
 	}
 
-	// getting the tasks from the db
-	lists, err := model.GetLists(userId)
+	// checking if the user has permission to view the lists
+	if !requireOwner(c, userId) {
+		return
+	}
+
+	// getting the lists from the db
+	filter := model.ListFilter{Name: c.Query("name")}
+	lists, info, err := h.Lists.GetLists(userId, filter, sort, page)
+	if err == model.ErrInvalidCursor {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid cursor."})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
 	}
 
+	setPageLinks(c, info)
 	c.JSON(http.StatusOK, lists)
 }
 
@@ -62,27 +79,16 @@ func GetListsByUserId(c *gin.Context) {
 // @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list."
 // @Failure      404  {object}  util.Error "If the list does not exist."
 // @Router       /lists/{url} [get]
-func GetListByUrl(c *gin.Context) {
-	// getting the url from the parameters
-	url := c.Param("url")
-
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
-		return
-	}
-
+func (h *Handler) GetListByUrl(c *gin.Context) {
 	// getting the list from the db
-	list, err := model.GetListByUrl(url)
+	list, err := h.Lists.GetListByUrl(c.Param("url"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, util.Error{Message: "List with this id does not exist."})
 		return
 	}
 
 	// checking whether the user has permission to view the list
-	if user.Id != list.OwnerId {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to view this list."})
+	if !h.requireRole(c, list.Id, model.RoleViewer) {
 		return
 	}
 
@@ -100,7 +106,7 @@ func GetListByUrl(c *gin.Context) {
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      500  {object}  util.Error "If there was a db error."
 // @Router       /lists [post]
-func CreateList(c *gin.Context) {
+func (h *Handler) CreateList(c *gin.Context) {
 	// binding the list from the body
 	var list model.List
 
@@ -116,23 +122,179 @@ func CreateList(c *gin.Context) {
 		return
 	}
 
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
+	// the logged-in user owns the list
+	list.OwnerId = loggedInUser(c).Id
+
+	// creating the list
+	created, err := h.Lists.CreateList(list)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
 	}
 
-	// changing the OwnerId
-	list.OwnerId = user.Id
+	// success
+	c.JSON(http.StatusCreated, created)
+}
 
-	// creating the list
-	created, err := model.CreateList(list)
+// @Summary      Edit list
+// @Description  Edits the list
+// @Tags         List endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 list body model.List true "List to edit"
+// @Param 		 id path int true "list ID"
+// @Success      202  {object}  model.List
+// @Failure      400  {object}  util.Error "If the list or the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the list does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /lists/{id} [put]
+func (h *Handler) EditList(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// checking whether the list exists and the user owns it
+	existingList, ok := h.authorizeList(c, id, model.RoleOwner)
+	if !ok {
+		return
+	}
+
+	// binding the list from the body
+	var list model.List
+
+	if err := c.ShouldBindBodyWith(&list, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid list."})
+		return
+	}
+
+	// validating the list
+	isValid, msg := list.Validate()
+	if !isValid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// only the name and the image can be changed
+	existingList.Name = list.Name
+	existingList.ImageUrl = list.ImageUrl
+
+	// saving the list in the db
+	saved, err := h.Lists.EditList(existingList)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
 	}
 
 	// success
-	c.JSON(http.StatusCreated, created)
+	c.JSON(http.StatusAccepted, saved)
+}
+
+// @Summary      Reorder list
+// @Description  Moves the list right before or after another list in the sidebar of the user
+// @Tags         List endpoints
+// @Accept       json
+// @Param 		 reorder body model.Reorder true "The list to move before or after"
+// @Param 		 id path int true "list ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the id or the target is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list."
+// @Failure      404  {object}  util.Error "If the list does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /lists/{id}/position [put]
+func (h *Handler) ReorderList(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// binding the target from the body
+	targetId, after, ok := bindReorder(c)
+	if !ok {
+		return
+	}
+
+	// every member can arrange their own sidebar
+	if _, ok := h.authorizeList(c, id, model.RoleViewer); !ok {
+		return
+	}
+
+	// moving the list
+	err = h.Lists.MoveList(loggedInUser(c).Id, id, targetId, after)
+	if err == model.ErrTargetNotFound {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The target list has to be in your sidebar."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary      Delete list
+// @Description  Moves the list together with all of its tasks to the trash
+// @Tags         List endpoints
+// @Param 		 id path int true "list ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the list does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /lists/{id} [delete]
+func (h *Handler) DeleteList(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// checking whether the list exists and the user owns it
+	if _, ok := h.authorizeList(c, id, model.RoleOwner); !ok {
+		return
+	}
+
+	// deleting the list and its tasks
+	err = h.Lists.DeleteList(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.Status(http.StatusAccepted)
+}
+
+// binds the reorder target from the body, exactly one of
+// the before and after ids has to be specified
+func bindReorder(c *gin.Context) (int, bool, bool) {
+	var reorder model.Reorder
+
+	if err := c.ShouldBindBodyWith(&reorder, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid target."})
+		return 0, false, false
+	}
+
+	// checking that exactly one target was specified
+	if (reorder.BeforeId == 0) == (reorder.AfterId == 0) {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify either beforeId or afterId."})
+		return 0, false, false
+	}
+
+	if reorder.AfterId != 0 {
+		return reorder.AfterId, true, true
+	}
+
+	return reorder.BeforeId, false, true
 }
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+// the list routes of main
+func listRouter(h *Handler) *gin.Engine {
+	r := gin.New()
+	api := r.Group("/api/v1", h.Authenticate)
+	api.GET("/lists/user/:userId", h.GetListsByUserId)
+	api.GET("/lists/:url", h.GetListByUrl)
+	api.POST("/lists", h.CreateList)
+	api.PUT("/lists/:id", h.EditList)
+	api.PUT("/lists/:id/position", h.ReorderList)
+	api.DELETE("/lists/:id", h.DeleteList)
+
+	return r
+}
+
+func listNames(t *testing.T, body []byte) []string {
+	t.Helper()
+
+	var lists []model.List
+	if err := json.Unmarshal(body, &lists); err != nil {
+		t.Fatalf("%s: %s", err, body)
+	}
+
+	names := []string{}
+	for _, list := range lists {
+		names = append(names, list.Name)
+	}
+
+	return names
+}
+
+func TestCreateList(t *testing.T) {
+	store := newFakeStore()
+	r := listRouter(store.handler())
+	user, cookie := store.login("john")
+
+	w := serve(r, "POST", "/api/v1/lists", `{"name":"Groceries","ownerId":99,"imageURL":"/evil.png"}`, cookie)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+
+	var created model.List
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.OwnerId != user.Id || created.ImageUrl != "" || store.lists[created.Id].Name != "Groceries" {
+		t.Fatalf("created = %+v, want a list of user %d without an image", created, user.Id)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"short name", `{"name":"ab"}`, http.StatusBadRequest},
+		{"long name", `{"name":"` + strings.Repeat("a", 33) + `"}`, http.StatusBadRequest},
+		{"invalid body", `{"name":`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		if w := serve(r, "POST", "/api/v1/lists", test.body, cookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, w.Code, test.status)
+		}
+	}
+
+	if w := serve(r, "POST", "/api/v1/lists", `{"name":"Groceries"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("without a session: status = %d, want 401", w.Code)
+	}
+}
+
+func TestGetLists(t *testing.T) {
+	store := newFakeStore()
+	r := listRouter(store.handler())
+	john, johnCookie := store.login("john")
+	jane, janeCookie := store.login("jane")
+
+	for _, name := range []string{"Work", "Home", "Books"} {
+		store.CreateList(model.List{OwnerId: john.Id, Name: name})
+	}
+	shared, _ := store.CreateList(model.List{OwnerId: jane.Id, Name: "Shared"})
+	store.CreateList(model.List{OwnerId: jane.Id, Name: "Private"})
+	store.CreateMember(model.Member{ListId: shared.Id, UserId: john.Id, Role: model.RoleViewer})
+
+	path := "/api/v1/lists/user/" + strconv.Itoa(john.Id)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Shared", "Books", "Home", "Work"}},
+		{"?sort=name", []string{"Books", "Home", "Shared", "Work"}},
+		{"?sort=created", []string{"Work", "Home", "Books", "Shared"}},
+		{"?sort=name&name=o", []string{"Books", "Home", "Work"}},
+	}
+
+	for _, test := range tests {
+		w := serve(r, "GET", path+test.query, "", johnCookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200: %s", test.query, w.Code, w.Body)
+		}
+		if names := listNames(t, w.Body.Bytes()); !reflect.DeepEqual(names, test.want) {
+			t.Errorf("%s: lists = %v, want %v", test.query, names, test.want)
+		}
+	}
+
+	// the page links lead to the next page
+	w := serve(r, "GET", path+"?sort=name&limit=3", "", johnCookie)
+	links, _ := pageLinks(w)
+	if names := listNames(t, w.Body.Bytes()); len(names) != 3 || links["next"] == "" {
+		t.Fatalf("first page = %v with links %v", names, links)
+	}
+	w = serve(r, "GET", links["next"], "", johnCookie)
+	if names := listNames(t, w.Body.Bytes()); !reflect.DeepEqual(names, []string{"Work"}) {
+		t.Errorf("second page = %v, want [Work]", names)
+	}
+
+	invalid := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"invalid id", "/api/v1/lists/user/john", http.StatusBadRequest},
+		{"invalid sort", path + "?sort=color", http.StatusBadRequest},
+		{"invalid limit", path + "?limit=0", http.StatusBadRequest},
+		{"invalid cursor", path + "?limit=1&cursor=nope", http.StatusBadRequest},
+	}
+
+	for _, test := range invalid {
+		if w := serve(r, "GET", test.path, "", johnCookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, w.Code, test.status)
+		}
+	}
+
+	// only the own sidebar can be listed
+	if w := serve(r, "GET", path, "", janeCookie); w.Code != http.StatusForbidden {
+		t.Errorf("the lists of another user: status = %d, want 403", w.Code)
+	}
+}
+
+func TestListRoles(t *testing.T) {
+	store := newFakeStore()
+	r := listRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	editor, editorCookie := store.login("editor")
+	_, strangerCookie := store.login("stranger")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Shared"})
+	store.CreateMember(model.Member{ListId: list.Id, UserId: editor.Id, Role: model.RoleEditor})
+
+	path := "/api/v1/lists/" + strconv.Itoa(list.Id)
+	rename := `{"name":"Renamed","imageURL":"/assets/images/list.png"}`
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		cookie string
+		status int
+	}{
+		{"viewing as a stranger", "GET", "/api/v1/lists/" + list.Url, "", strangerCookie, http.StatusForbidden},
+		{"viewing as a member", "GET", "/api/v1/lists/" + list.Url, "", editorCookie, http.StatusOK},
+		{"viewing an unknown list", "GET", "/api/v1/lists/unknown", "", ownerCookie, http.StatusNotFound},
+		{"editing as an editor", "PUT", path, rename, editorCookie, http.StatusForbidden},
+		{"editing an unknown list", "PUT", "/api/v1/lists/999", rename, ownerCookie, http.StatusNotFound},
+		{"editing with a short name", "PUT", path, `{"name":"ab"}`, ownerCookie, http.StatusBadRequest},
+		{"editing as the owner", "PUT", path, rename, ownerCookie, http.StatusAccepted},
+		{"deleting as an editor", "DELETE", path, "", editorCookie, http.StatusForbidden},
+		{"deleting with an invalid id", "DELETE", "/api/v1/lists/first", "", ownerCookie, http.StatusBadRequest},
+		{"deleting as the owner", "DELETE", path, "", ownerCookie, http.StatusAccepted},
+		{"deleting again", "DELETE", path, "", ownerCookie, http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		if w := serve(r, step.method, step.path, step.body, step.cookie); w.Code != step.status {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.status, w.Body)
+		}
+
+		// the edit only changes the name and the image
+		if step.name == "editing as the owner" {
+			edited := store.lists[list.Id]
+			if edited.Name != "Renamed" || edited.ImageUrl != "/assets/images/list.png" || edited.OwnerId != owner.Id || edited.Url != list.Url {
+				t.Errorf("edited list = %+v", edited)
+			}
+		}
+	}
+}
+
+func TestReorderList(t *testing.T) {
+	store := newFakeStore()
+	r := listRouter(store.handler())
+	john, johnCookie := store.login("john")
+	jane, janeCookie := store.login("jane")
+
+	work, _ := store.CreateList(model.List{OwnerId: john.Id, Name: "Work"})
+	home, _ := store.CreateList(model.List{OwnerId: john.Id, Name: "Home"})
+	shared, _ := store.CreateList(model.List{OwnerId: jane.Id, Name: "Shared"})
+	store.CreateMember(model.Member{ListId: shared.Id, UserId: john.Id, Role: model.RoleViewer})
+
+	reorder := func(id int, body string, cookie string) int {
+		return serve(r, "PUT", "/api/v1/lists/"+strconv.Itoa(id)+"/position", body, cookie).Code
+	}
+	sidebar := func(user model.User, cookie string) []string {
+		return listNames(t, serve(r, "GET", "/api/v1/lists/user/"+strconv.Itoa(user.Id), "", cookie).Body.Bytes())
+	}
+
+	// a viewer can arrange the shared list in their own sidebar
+	if status := reorder(shared.Id, `{"afterId":`+strconv.Itoa(work.Id)+`}`, johnCookie); status != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", status)
+	}
+	if names := sidebar(john, johnCookie); !reflect.DeepEqual(names, []string{"Home", "Work", "Shared"}) {
+		t.Errorf("sidebar of john = %v", names)
+	}
+	if names := sidebar(jane, janeCookie); !reflect.DeepEqual(names, []string{"Shared"}) {
+		t.Errorf("sidebar of jane = %v", names)
+	}
+
+	tests := []struct {
+		name   string
+		id     int
+		body   string
+		cookie string
+		status int
+	}{
+		{"no target", home.Id, `{}`, johnCookie, http.StatusBadRequest},
+		{"both targets", home.Id, `{"beforeId":1,"afterId":2}`, johnCookie, http.StatusBadRequest},
+		{"target outside the sidebar", shared.Id, `{"beforeId":999}`, johnCookie, http.StatusBadRequest},
+		{"list of another user", home.Id, `{"beforeId":` + strconv.Itoa(shared.Id) + `}`, janeCookie, http.StatusForbidden},
+		{"unknown list", 999, `{"beforeId":` + strconv.Itoa(home.Id) + `}`, johnCookie, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		if status := reorder(test.id, test.body, test.cookie); status != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, status, test.status)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// @Summary      Get list members
+// @Description  Returns all the users the specified list is shared with
+// @Tags         Member endpoints
+// @Produce      json
+// @Param 		 listId path int true "list ID"
+// @Success      200  {array}   model.Member
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list."
+// @Failure      404  {object}  util.Error "If the list with this id does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /members/list/{listId} [get]
+func (h *Handler) GetMembersByListId(c *gin.Context) {
+	// parsing the listId parameter
+	listId, err := strconv.Atoi(c.Param("listId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// checking whether the list exists and the user can view it
+	if _, ok := h.authorizeList(c, listId, model.RoleViewer); !ok {
+		return
+	}
+
+	// getting the members from the db
+	members, err := h.Lists.GetMembers(listId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// @Summary      Invite member
+// @Description  Shares the list with the user with the specified email
+// @Description  The role can be viewer or editor, only the creator of the list is its owner
+// @Tags         Member endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 member body model.InviteMember true "User to invite"
+// @Success      201  {object}  model.Member
+// @Failure      400  {object}  util.Error "If the invitation is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user doesn't have permission to share the list."
+// @Failure      404  {object}  util.Error "If the list or the invited user does not exist."
+// @Failure      409  {object}  util.Error "If the invited user already has access to the list."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /members [post]
+func (h *Handler) InviteMember(c *gin.Context) {
+	// binding the invitation from the body
+	var invite model.InviteMember
+
+	if err := c.ShouldBindBodyWith(&invite, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid invitation."})
+		return
+	}
+
+	// validating the role
+	member := model.Member{ListId: invite.ListId, Role: invite.Role}
+	isValid, msg := member.Validate()
+	if !isValid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// checking whether the list exists and the user can share it
+	list, ok := h.authorizeList(c, invite.ListId, model.RoleOwner)
+	if !ok {
+		return
+	}
+
+	// getting the invited user from the db
+	invited, err := h.Users.GetUserByEmail(invite.Email)
+	if err != nil || invited.Id == 0 {
+		c.JSON(http.StatusNotFound, util.Error{Message: "User with this email does not exist."})
+		return
+	}
+
+	// checking if the invited user already has access to the list
+	if invited.Id == list.OwnerId || h.Lists.IsMember(list.Id, invited.Id) {
+		c.JSON(http.StatusConflict, util.Error{Message: "This user already has access to the list."})
+		return
+	}
+
+	// creating the member
+	member.UserId = invited.Id
+	created, err := h.Lists.CreateMember(member)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	created.Name = invited.Name
+	created.Email = invited.Email
+	c.JSON(http.StatusCreated, created)
+}
+
+// @Summary      Change member role
+// @Description  Changes the role of the member
+// @Description  The role can be viewer or editor, only the creator of the list is its owner
+// @Tags         Member endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 member body model.Member true "Member with the new role"
+// @Param 		 id path int true "member ID"
+// @Success      202  {object}  model.Member
+// @Failure      400  {object}  util.Error "If the role or the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the member does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /members/{id} [put]
+func (h *Handler) EditMember(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// checking whether the member exists
+	existingMember, err := h.Lists.GetMemberById(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Member with this ID does not exist."})
+		return
+	}
+
+	// checking if the user has permission to manage the members
+	if !h.requireRole(c, existingMember.ListId, model.RoleOwner) {
+		return
+	}
+
+	// binding the member from the body
+	var member model.Member
+
+	if err := c.ShouldBindBodyWith(&member, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid member."})
+		return
+	}
+
+	// validating the member
+	isValid, msg := member.Validate()
+	if !isValid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// only the role can be changed
+	existingMember.Role = member.Role
+
+	// saving the member in the db
+	saved, err := h.Lists.EditMember(existingMember)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.JSON(http.StatusAccepted, saved)
+}
+
+// @Summary      Remove member
+// @Description  Removes the member from the list, members can also remove themselves
+// @Tags         Member endpoints
+// @Param 		 id path int true "member ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the member does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /members/{id} [delete]
+func (h *Handler) RemoveMember(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// checking whether the member exists
+	member, err := h.Lists.GetMemberById(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Member with this ID does not exist."})
+		return
+	}
+
+	// checking if the user has permission,
+	// the owners can remove anyone and the members can leave the list
+	if loggedInUser(c).Id != member.UserId && !h.requireRole(c, member.ListId, model.RoleOwner) {
+		return
+	}
+
+	// deleting the member
+	err = h.Lists.DeleteMember(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+// the member routes of main
+func memberRouter(h *Handler) *gin.Engine {
+	r := gin.New()
+	api := r.Group("/api/v1", h.Authenticate)
+	api.GET("/members/list/:listId", h.GetMembersByListId)
+	api.POST("/members", h.InviteMember)
+	api.PUT("/members/:id", h.EditMember)
+	api.DELETE("/members/:id", h.RemoveMember)
+
+	return r
+}
+
+func TestMemberRoles(t *testing.T) {
+	store := newFakeStore()
+	r := memberRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	_, viewerCookie := store.login("viewer")
+	store.login("editor")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Shared"})
+	invite := func(email string, role string) string {
+		return `{"listId":` + strconv.Itoa(list.Id) + `,"email":"` + email + `","role":"` + role + `"}`
+	}
+
+	w := serve(r, "POST", "/api/v1/members", invite("viewer@example.com", model.RoleViewer), ownerCookie)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+	var viewer model.Member
+	json.Unmarshal(w.Body.Bytes(), &viewer)
+	path := "/api/v1/members/" + strconv.Itoa(viewer.Id)
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		cookie string
+		status int
+	}{
+		{"inviting as the owner role", "POST", "/api/v1/members", invite("editor@example.com", model.RoleOwner), ownerCookie, http.StatusBadRequest},
+		{"inviting with an unknown role", "POST", "/api/v1/members", invite("editor@example.com", "admin"), ownerCookie, http.StatusBadRequest},
+		{"inviting as a viewer", "POST", "/api/v1/members", invite("editor@example.com", model.RoleEditor), viewerCookie, http.StatusForbidden},
+		{"inviting an unknown user", "POST", "/api/v1/members", invite("nobody@example.com", model.RoleEditor), ownerCookie, http.StatusNotFound},
+		{"inviting a member again", "POST", "/api/v1/members", invite("viewer@example.com", model.RoleEditor), ownerCookie, http.StatusConflict},
+		{"inviting the owner", "POST", "/api/v1/members", invite("owner@example.com", model.RoleEditor), ownerCookie, http.StatusConflict},
+		{"changing to the owner role", "PUT", path, `{"role":"owner"}`, ownerCookie, http.StatusBadRequest},
+		{"changing the own role", "PUT", path, `{"role":"editor"}`, viewerCookie, http.StatusForbidden},
+		{"changing to the editor role", "PUT", path, `{"role":"editor"}`, ownerCookie, http.StatusAccepted},
+		{"leaving the list", "DELETE", path, "", viewerCookie, http.StatusAccepted},
+		{"removing again", "DELETE", path, "", ownerCookie, http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		if w := serve(r, step.method, step.path, step.body, step.cookie); w.Code != step.status {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.status, w.Body)
+		}
+
+		if step.name == "changing to the owner role" && store.members[viewer.Id].Role != model.RoleViewer {
+			t.Errorf("role = %s after the rejected change", store.members[viewer.Id].Role)
+		}
+	}
+}
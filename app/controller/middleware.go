@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+// the keys of the values Authenticate saves in the context
+const (
+	userKey    = "user"
+	sessionKey = "session"
+)
+
+// the middleware of the routes that need a logged-in user
+// the user is resolved once, from the personal access token or the session cookie,
+// and the handlers get it with loggedInUser
+func (h *Handler) Authenticate(c *gin.Context) {
+	// scripts authenticate with a personal access token instead of the cookies
+	if raw, ok := model.BearerToken(c.GetHeader("Authorization")); ok {
+		h.authenticateToken(c, raw)
+		return
+	}
+
+	session, err := h.Sessions.GetLoggedInSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		return
+	}
+
+	user, err := h.Users.GetUserById(session.UserId)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		return
+	}
+
+	c.Set(userKey, user)
+	c.Set(sessionKey, session)
+	c.Next()
+}
+
+// checks the personal access token of the request
+// every token can read, but the changes need the scope of the resource
+func (h *Handler) authenticateToken(c *gin.Context, raw string) {
+	token, err := h.AccessTokens.AuthenticateAccessToken(raw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, util.Error{Message: "The access token is invalid or has expired."})
+		return
+	}
+
+	scope, allowed := model.RequiredScope(c.Request.Method, c.Request.URL.Path)
+	if !allowed {
+		c.AbortWithStatusJSON(http.StatusForbidden, util.Error{Message: "This endpoint can't be used with an access token."})
+		return
+	}
+
+	if scope != model.ScopeRead && !token.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, util.Error{Message: fmt.Sprintf("The access token needs the %s scope.", scope)})
+		return
+	}
+
+	user, err := h.Users.GetUserById(token.UserId)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, util.Error{Message: "The access token is invalid or has expired."})
+		return
+	}
+
+	c.Set(userKey, user)
+	c.Next()
+}
+
+// returns the user saved by Authenticate
+// it can only be used in the handlers of the authenticated routes
+func loggedInUser(c *gin.Context) model.User {
+	return c.MustGet(userKey).(model.User)
+}
+
+// returns the session of the request, if the user logged in with the cookies instead of an access token
+func loggedInSession(c *gin.Context) (model.Session, bool) {
+	session, ok := c.Get(sessionKey)
+	if !ok {
+		return model.Session{}, false
+	}
+
+	return session.(model.Session), true
+}
+
+// the handlers of the sessions, the tokens and the security settings need a session,
+// so an access token can't be used to change them
+// it writes the error response and returns false if the request has no session
+func requireSession(c *gin.Context) (model.User, model.Session, bool) {
+	session, ok := loggedInSession(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		return model.User{}, model.Session{}, false
+	}
+
+	return loggedInUser(c), session, true
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/oidc"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+// how long the user has to log in at the provider
+const oidcFlowTTL = 10 * time.Minute
+
+// @Summary      Get OIDC providers
+// @Description  Returns the names of the configured OpenID Connect providers.
+// @Tags         User endpoints
+// @Produce      json
+// @Success      200  {array}   string
+// @Router       /oidc/providers [get]
+func (h *Handler) GetOidcProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, oidc.Providers())
+}
+
+// @Summary      OIDC login
+// @Description  Redirects to the login page of the OpenID Connect provider.
+// @Description  The provider redirects back to the callback endpoint, which logs in the user.
+// @Tags         User endpoints
+// @Param        provider path string true "Name of the provider"
+// @Success      302
+// @Failure      404  {object}  util.Error "If there is no provider with this name."
+// @Failure      502  {object}  util.Error "If the provider can't be reached."
+// @Router       /oidc/{provider}/login [get]
+func (h *Handler) OidcLogin(c *gin.Context) {
+	// getting the provider from the url
+	provider, err := oidc.GetProvider(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Provider not found."})
+		return
+	}
+
+	// creating the state, the nonce and the pkce verifier
+	flow, err := oidc.NewFlow(provider.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to start the login."})
+		return
+	}
+
+	url, err := provider.AuthUrl(flow)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, util.Error{Message: "Failed to reach the provider."})
+		return
+	}
+
+	// the flow is kept in a signed cookie until the callback
+	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"provider": flow.Provider,
+		"state":    flow.State,
+		"nonce":    flow.Nonce,
+		"verifier": flow.Verifier,
+		"exp":      time.Now().Add(oidcFlowTTL).Unix(),
+	})
+
+	cookie, err := claims.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to start the login."})
+		return
+	}
+
+	c.SetCookie("oidc", cookie, int(oidcFlowTTL.Seconds()), "/api/v1/oidc", "localhost", false, true)
+	c.Redirect(http.StatusFound, url)
+}
+
+// @Summary      OIDC callback
+// @Description  Logs in the user after the OpenID Connect provider redirected back.
+// @Description  On the first login the user with the same verified email is linked, or a new user is created without a password.
+// @Description  Users with 2FA are redirected to the frontend with an mfaToken in the fragment of the url instead of a session,
+// @Description  and the login has to be finished with a code at /login/2fa, the same as after the password.
+// @Tags         User endpoints
+// @Param        provider path string true "Name of the provider"
+// @Param        code query string true "Authorization code"
+// @Param        state query string true "State of the login"
+// @Success      302
+// @Failure      400  {object}  util.Error "If the login is invalid or has expired."
+// @Failure      403  {object}  util.Error "If the email of the account is not verified by the provider."
+// @Failure      404  {object}  util.Error "If there is no provider with this name."
+// @Failure      502  {object}  util.Error "If the provider rejected the login."
+// @Router       /oidc/{provider}/callback [get]
+func (h *Handler) OidcCallback(c *gin.Context) {
+	// getting the provider from the url
+	provider, err := oidc.GetProvider(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Provider not found."})
+		return
+	}
+
+	// if the user cancelled the login
+	if msg := c.Query("error"); msg != "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: fmt.Sprintf("The provider returned an error: %s", msg)})
+		return
+	}
+
+	// getting the flow from the cookie, which can only be used once
+	flow, ok := getOidcFlow(c)
+	c.SetCookie("oidc", "", -3600, "/api/v1/oidc", "localhost", false, true)
+
+	if !ok || flow.Provider != provider.Name || flow.State != c.Query("state") || c.Query("code") == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The login is invalid or has expired. Please try again."})
+		return
+	}
+
+	// exchanging the code and validating the id token
+	idToken, err := provider.Exchange(c.Query("code"), flow.Verifier)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, util.Error{Message: "The provider rejected the login."})
+		return
+	}
+
+	claims, err := provider.Verify(idToken, flow.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, util.Error{Message: "The provider returned an invalid id token."})
+		return
+	}
+
+	// the users are linked by email, so it has to be verified
+	if claims.Email == "" || !claims.EmailVerified {
+		c.JSON(http.StatusForbidden, util.Error{Message: "The email address of your account is not verified."})
+		return
+	}
+
+	// getting, linking or creating the user
+	user, err := h.Users.LoginWithIdentity(provider.Name, claims.Subject, claims.Email, claims.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+
+	// users with 2fa get a token for the second step instead of a session,
+	// it's in the fragment of the url, so it's not sent to any server
+	if user.TotpEnabled {
+		token, err := h.Tokens.CreateToken(user.Id, model.TokenMfa, mfaTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+			return
+		}
+
+		c.Redirect(http.StatusFound, os.Getenv("FRONTEND")+"/#"+url.Values{"mfaToken": {token}}.Encode())
+		return
+	}
+
+	// logging in the user
+	if err := h.startSession(c, user); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+
+	c.Redirect(http.StatusFound, os.Getenv("FRONTEND"))
+}
+
+func getOidcFlow(c *gin.Context) (oidc.Flow, bool) {
+	cookie, err := c.Cookie("oidc")
+	if err != nil {
+		return oidc.Flow{}, false
+	}
+
+	token, err := jwt.Parse(cookie, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %s", t.Header["alg"])
+		}
+
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil || !token.Valid {
+		return oidc.Flow{}, false
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	flow := oidc.Flow{}
+	flow.Provider, _ = claims["provider"].(string)
+	flow.State, _ = claims["state"].(string)
+	flow.Nonce, _ = claims["nonce"].(string)
+	flow.Verifier, _ = claims["verifier"].(string)
+
+	return flow, flow.State != ""
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+// the maximum number of rows on a page
+const maxPageLimit = 100
+
+// parses the limit and the cursor query parameters,
+// otherwise it writes the error response and returns false
+func bindPage(c *gin.Context) (model.Page, bool) {
+	var page model.Page
+
+	// no limit means every row
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil || page.Limit < 1 || page.Limit > maxPageLimit {
+			c.JSON(http.StatusBadRequest, util.Error{Message: fmt.Sprintf("The limit has to be between 1 and %d.", maxPageLimit)})
+			return model.Page{}, false
+		}
+	}
+
+	// a cursor can only be used with a limit
+	page.Cursor = c.Query("cursor")
+	if page.Cursor != "" && page.Limit == 0 {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The cursor can only be used with a limit."})
+		return model.Page{}, false
+	}
+
+	return page, true
+}
+
+// parses an optional RFC 3339 time query parameter,
+// otherwise it writes the error response and returns false
+func bindTimeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: fmt.Sprintf("The %s has to be an RFC 3339 time.", name)})
+		return nil, false
+	}
+
+	return &t, true
+}
+
+// sets the Link header with the urls of the next and the previous pages
+func setPageLinks(c *gin.Context, info model.PageInfo) {
+	links := []string{}
+
+	// the links are always in the same order, next first
+	for _, page := range []struct{ Rel, Cursor string }{{"next", info.Next}, {"prev", info.Prev}} {
+		if page.Cursor == "" {
+			continue
+		}
+
+		// the link is the current url with the new cursor
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("cursor", page.Cursor)
+		u.RawQuery = query.Encode()
+
+		links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), page.Rel))
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/model"
+)
+
+var linkPattern = regexp.MustCompile(`<([^>]+)>; rel="(\w+)"`)
+
+// returns the urls of the Link header by their rel, and the rels in their order
+func pageLinks(w *httptest.ResponseRecorder) (map[string]string, []string) {
+	links := map[string]string{}
+	rels := []string{}
+	for _, match := range linkPattern.FindAllStringSubmatch(w.Header().Get("Link"), -1) {
+		links[match[2]] = match[1]
+		rels = append(rels, match[2])
+	}
+
+	return links, rels
+}
+
+func responseTaskIds(t *testing.T, w *httptest.ResponseRecorder) []int {
+	t.Helper()
+
+	var tasks []model.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+		t.Fatalf("%s: %s", err, w.Body)
+	}
+
+	ids := []int{}
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+
+	return ids
+}
+
+func TestTaskPagesOverHttp(t *testing.T) {
+	r, store := newIntegrationRouter(t)
+	user, cookie := loginTestUser(t, store, "Paging User")
+
+	list, err := store.CreateList(model.List{OwnerId: user.Id, Name: "Paged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := store.CreateTask(model.Task{ListId: list.Id, CreatedById: &user.Id, Title: "Task " + strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := "/api/v1/tasks/list/" + strconv.Itoa(list.Id)
+	all := serve(r, "GET", path+"?sort=created", "", cookie)
+	if all.Code != http.StatusOK || all.Header().Get("Link") != "" {
+		t.Fatalf("GET %s = %d with Link %q", path, all.Code, all.Header().Get("Link"))
+	}
+
+	// following the next links returns every task once
+	got := []int{}
+	next := path + "?sort=created&limit=2"
+	for page := 0; next != ""; page++ {
+		w := serve(r, "GET", next, "", cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", next, w.Code, w.Body)
+		}
+		got = append(got, responseTaskIds(t, w)...)
+
+		links, rels := pageLinks(w)
+		if page > 0 && rels[len(rels)-1] != "prev" {
+			t.Errorf("page %d: links = %v, want next before prev", page, rels)
+		}
+		if page > 0 && links["prev"] == "" {
+			t.Errorf("page %d has no prev link", page)
+		}
+
+		next = links["next"]
+	}
+
+	if want := responseTaskIds(t, all); !reflect.DeepEqual(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+
+	if w := serve(r, "GET", path+"?limit=2&cursor=nope", "", cookie); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid cursor: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+// checks whether the logged-in user has at least the role in the list,
+// otherwise it writes the error response and returns false
+func (h *Handler) requireRole(c *gin.Context, listId int, role string) bool {
+	if h.Lists.HasRole(listId, loggedInUser(c).Id, role) {
+		return true
+	}
+
+	msg := "You do not have permission to do this."
+	if role == model.RoleViewer {
+		msg = "You do not have permission to view this list."
+	}
+
+	c.JSON(http.StatusForbidden, util.Error{Message: msg})
+	return false
+}
+
+// returns the list if it exists and the logged-in user has the role in it,
+// otherwise it writes the error response and returns false
+func (h *Handler) authorizeList(c *gin.Context, id int, role string) (model.List, bool) {
+	list, exists := h.Lists.ListExists(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, util.Error{Message: "List with this ID does not exist."})
+		return model.List{}, false
+	}
+
+	return list, h.requireRole(c, list.Id, role)
+}
+
+// returns the task if it exists and the logged-in user has the role in its list,
+// otherwise it writes the error response and returns false
+func (h *Handler) authorizeTask(c *gin.Context, id int, role string) (model.Task, bool) {
+	task, exists := h.Tasks.TaskExists(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID does not exist."})
+		return model.Task{}, false
+	}
+
+	return task, h.requireRole(c, task.ListId, role)
+}
+
+// the same as authorizeTask, but the task is looked up by its url
+func (h *Handler) authorizeTaskUrl(c *gin.Context, url string, role string) (model.Task, bool) {
+	task, err := h.Tasks.GetTaskByUrl(url, loggedInUser(c).Id)
+	if err != nil || task.Id == 0 {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this URL does not exist."})
+		return model.Task{}, false
+	}
+
+	return task, h.requireRole(c, task.ListId, role)
+}
+
+// checks whether the resource belongs to the logged-in user,
+// otherwise it writes the error response and returns false
+func requireOwner(c *gin.Context, ownerId int) bool {
+	if loggedInUser(c).Id == ownerId {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to do this."})
+	return false
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/0l1v3rr/todo/app/ratelimit"
+)
+
+// the limits of the requests per ip address
+var (
+	LoginLimit    = ratelimit.PerMinute(10)
+	RegisterLimit = ratelimit.PerHour(10)
+	EmailLimit    = ratelimit.PerMinute(5)
+	UploadLimit   = ratelimit.PerMinute(20)
+)
+
+// the limit of the login attempts per account
+var accountLoginLimit = ratelimit.PerMinute(10)
+
+// an account is locked for a minute after 5 failed logins, which is doubled with every further failure
+var loginLockout = ratelimit.Lockout{
+	Threshold: 5,
+	Base:      time.Minute,
+	Max:       15 * time.Minute,
+	Window:    time.Hour,
+}
+
+// a bcrypt hash with the cost of the passwords, which is checked when the email is not registered
+const dummyHash = "$2a$14$hpsLvNjNk4wEim.nAxf4D.1zyky5zTc.3Peu4IJFqx9eTYurLSI5S"
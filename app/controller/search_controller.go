@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Search
+// @Description  Searches the tasks and the lists the user has access to.
+// @Description  The query can contain the is:done, is:open, list:<url>, created:<from>..<to> and due:<from>..<to> filters,
+// @Description  where the dates are in YYYY-MM-DD format and either end of the range can be left empty.
+// @Description  Every match is ranked by relevance, and the best 50 tasks and lists are returned.
+// @Tags         Search endpoints
+// @Produce      json
+// @Param 		 q query string true "search query"
+// @Param 		 tz query string false "the IANA time zone of the dates in the query" default(UTC)
+// @Success      200  {array}   model.SearchResult
+// @Failure      400  {object}  util.Error "If the query or the time zone is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	// checking the length of the query
+	q := c.Query("q")
+	if len(q) > 256 {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The query can be maximum 256 characters long."})
+		return
+	}
+
+	// parsing the time zone
+	loc, err := util.LoadLocation(c.Query("tz"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid time zone."})
+		return
+	}
+
+	// parsing the query
+	query, err := model.ParseSearchQuery(q, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid query: " + err.Error()})
+		return
+	}
+
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// searching in the db
+	results, err := h.Searcher.Search(user.Id, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
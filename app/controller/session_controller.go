@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// how long a jwt is valid, it can be renewed with the refresh token
+	accessTTL = 15 * time.Minute
+
+	// how long a session lasts without being refreshed
+	refreshTTL = 30 * 24 * time.Hour
+)
+
+// @Summary      Get sessions
+// @Description  Returns the active sessions of the logged-in user, the most recently used first.
+// @Tags         Session endpoints
+// @Produce      json
+// @Success      200  {array}   model.Session
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /sessions [get]
+func (h *Handler) GetSessions(c *gin.Context) {
+	// getting the logged-in user, this can't be done with an access token
+	user, current, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// getting the sessions from the db
+	sessions, err := h.Sessions.GetSessions(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// marking the session of the request
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == current.Id
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @Summary      Revoke session
+// @Description  Revokes a session of the logged-in user, which logs out the device of the session.
+// @Tags         Session endpoints
+// @Produce      json
+// @Param        id path int true "Session ID"
+// @Success      200  {object}  util.Success "If the session has been revoked."
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      404  {object}  util.Error "If the user has no active session with this id."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	// getting the id from the url
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid id."})
+		return
+	}
+
+	// getting the logged-in user, this can't be done with an access token
+	user, current, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// revoking the session
+	err = h.Sessions.RevokeSession(user.Id, id)
+	if err == model.ErrSessionNotFound {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Session not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// if the current session has been revoked, its cookies are useless
+	if id == current.Id {
+		clearSessionCookies(c)
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "The session has been revoked."})
+}
+
+// @Summary      Log out everywhere
+// @Description  Revokes every session of the logged-in user, including the current one.
+// @Tags         Session endpoints
+// @Produce      json
+// @Success      200  {object}  util.Success "If the sessions have been revoked."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /sessions [delete]
+func (h *Handler) RevokeAllSessions(c *gin.Context) {
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// revoking every session
+	if err := h.Sessions.RevokeSessions(user.Id, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	clearSessionCookies(c)
+	c.JSON(http.StatusOK, util.Success{Message: "You have been logged out everywhere."})
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// @Summary      Get tags
+// @Description  Returns all the tags of the logged-in user
+// @Tags         Tag endpoints
+// @Produce      json
+// @Success      200  {array}   model.Tag
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tags [get]
+func (h *Handler) GetTags(c *gin.Context) {
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// getting the tags from the db
+	tags, err := h.Tags.GetTags(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// @Summary      Create tag
+// @Description  Creates a new tag
+// @Tags         Tag endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 tag body model.Tag true "Tag to create"
+// @Success      201  {object}  model.Tag
+// @Failure      400  {object}  util.Error "If the tag is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      409  {object}  util.Error "If the user already has a tag with this name."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tags [post]
+func (h *Handler) CreateTag(c *gin.Context) {
+	// binding the tag from the body
+	var tag model.Tag
+
+	if err := c.ShouldBindBodyWith(&tag, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid tag."})
+		return
+	}
+
+	// validating the tag
+	isValid, msg := tag.Validate()
+	if !isValid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// checking if the name is already used
+	if h.Tags.TagNameExists(user.Id, tag.Name, 0) {
+		c.JSON(http.StatusConflict, util.Error{Message: "You already have a tag with this name."})
+		return
+	}
+
+	// creating the tag
+	tag.Id = 0
+	tag.OwnerId = user.Id
+	created, err := h.Tags.CreateTag(tag)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// @Summary      Edit tag
+// @Description  Renames or recolors the tag
+// @Tags         Tag endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 tag body model.Tag true "Tag to edit"
+// @Param 		 id path int true "tag ID"
+// @Success      202  {object}  model.Tag
+// @Failure      400  {object}  util.Error "If the tag or the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the tag does not exist."
+// @Failure      409  {object}  util.Error "If the user already has a tag with this name."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tags/{id} [put]
+func (h *Handler) EditTag(c *gin.Context) {
+	// getting the tag of the user from the path
+	existingTag, ok := h.getOwnTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	// binding the tag from the body
+	var tag model.Tag
+
+	if err := c.ShouldBindBodyWith(&tag, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid tag."})
+		return
+	}
+
+	// validating the tag
+	isValid, msg := tag.Validate()
+	if !isValid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// checking if the new name is already used
+	if h.Tags.TagNameExists(existingTag.OwnerId, tag.Name, existingTag.Id) {
+		c.JSON(http.StatusConflict, util.Error{Message: "You already have a tag with this name."})
+		return
+	}
+
+	// only the name and the color can be changed
+	existingTag.Name = tag.Name
+	existingTag.Color = tag.Color
+
+	// saving the tag in the db
+	saved, err := h.Tags.EditTag(existingTag)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, saved)
+}
+
+// @Summary      Merge tags
+// @Description  Moves every task of the tag to another tag and deletes the tag
+// @Tags         Tag endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 merge body model.MergeTags true "The tag to merge into"
+// @Param 		 id path int true "ID of the tag to merge"
+// @Success      202  {object}  model.Tag
+// @Failure      400  {object}  util.Error "If the ids are not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If one of the tags does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tags/{id}/merge [post]
+func (h *Handler) MergeTag(c *gin.Context) {
+	// getting the tag of the user from the path
+	from, ok := h.getOwnTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	// binding the target from the body
+	var merge model.MergeTags
+
+	if err := c.ShouldBindBodyWith(&merge, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid tag to merge into."})
+		return
+	}
+
+	// getting the target tag of the user
+	into, ok := h.getOwnTag(c, strconv.Itoa(merge.IntoId))
+	if !ok {
+		return
+	}
+
+	// a tag can not be merged into itself
+	if from.Id == into.Id {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "A tag can not be merged into itself."})
+		return
+	}
+
+	// merging the tags
+	err := h.Tags.MergeTag(from.Id, into.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, into)
+}
+
+// @Summary      Delete tag
+// @Description  Deletes the tag and removes it from every task
+// @Tags         Tag endpoints
+// @Param 		 id path int true "tag ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the tag does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tags/{id} [delete]
+func (h *Handler) DeleteTag(c *gin.Context) {
+	// getting the tag of the user from the path
+	tag, ok := h.getOwnTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	// deleting the tag
+	err := h.Tags.DeleteTag(tag.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// returns the tag with the given id if it belongs to the logged-in user,
+// otherwise it writes the error response and returns false
+func (h *Handler) getOwnTag(c *gin.Context, param string) (model.Tag, bool) {
+	// parsing the id
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return model.Tag{}, false
+	}
+
+	// checking if the tag exists
+	tag, err := h.Tags.GetTagById(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Tag with this ID does not exist."})
+		return model.Tag{}, false
+	}
+
+	// checking if the tag belongs to the user
+	return tag, requireOwner(c, tag.OwnerId)
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// the tag routes of main
+func tagRouter(h *Handler) *gin.Engine {
+	r := gin.New()
+	api := r.Group("/api/v1", h.Authenticate)
+	api.GET("/tags", h.GetTags)
+	api.POST("/tags", h.CreateTag)
+	api.PUT("/tags/:id", h.EditTag)
+	api.POST("/tags/:id/merge", h.MergeTag)
+	api.DELETE("/tags/:id", h.DeleteTag)
+
+	return r
+}
+
+// sends the request with the session cookie, or with the access token if it starts with Bearer
+func serve(r *gin.Engine, method string, path string, body string, auth string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	if strings.HasPrefix(auth, "Bearer ") {
+		req.Header.Set("Authorization", auth)
+	} else if auth != "" {
+		req.AddCookie(&http.Cookie{Name: "jwt", Value: auth})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTagsNeedLogin(t *testing.T) {
+	store := newFakeStore()
+	r := tagRouter(store.handler())
+
+	for _, auth := range []string{"", "unknown-session", "Bearer todo_unknown"} {
+		if w := serve(r, "GET", "/api/v1/tags", "", auth); w.Code != http.StatusUnauthorized {
+			t.Errorf("%q: status = %d, want 401", auth, w.Code)
+		}
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	store := newFakeStore()
+	r := tagRouter(store.handler())
+	user, cookie := store.login("john")
+
+	w := serve(r, "POST", "/api/v1/tags", `{"name":"urgent","color":"#ef4444","ownerId":99}`, cookie)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+
+	var created model.Tag
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.OwnerId != user.Id || store.tags[created.Id].Name != "urgent" {
+		t.Fatalf("created = %+v, want a tag of user %d", created, user.Id)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"same name", `{"name":"urgent","color":"#000000"}`, http.StatusConflict},
+		{"invalid color", `{"name":"later","color":"red"}`, http.StatusBadRequest},
+		{"empty name", `{"name":"","color":"#000000"}`, http.StatusBadRequest},
+		{"invalid body", `{"name":`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		if w := serve(r, "POST", "/api/v1/tags", test.body, cookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, w.Code, test.status)
+		}
+	}
+}
+
+func TestTagsOfOtherUsers(t *testing.T) {
+	store := newFakeStore()
+	r := tagRouter(store.handler())
+	john, johnCookie := store.login("john")
+	jane, janeCookie := store.login("jane")
+
+	johns, _ := store.CreateTag(model.Tag{OwnerId: john.Id, Name: "work", Color: "#000000"})
+	store.CreateTag(model.Tag{OwnerId: jane.Id, Name: "home", Color: "#ffffff"})
+
+	// the users only get their own tags
+	w := serve(r, "GET", "/api/v1/tags", "", janeCookie)
+	var tags []model.Tag
+	json.Unmarshal(w.Body.Bytes(), &tags)
+	if w.Code != http.StatusOK || len(tags) != 1 || tags[0].Name != "home" {
+		t.Fatalf("GET /tags = %d %s, want only the tag of jane", w.Code, w.Body)
+	}
+
+	path := "/api/v1/tags/" + strconv.Itoa(johns.Id)
+	if w := serve(r, "PUT", path, `{"name":"mine","color":"#000000"}`, janeCookie); w.Code != http.StatusForbidden {
+		t.Errorf("editing the tag of another user: status = %d, want 403", w.Code)
+	}
+	if w := serve(r, "DELETE", path, "", janeCookie); w.Code != http.StatusForbidden {
+		t.Errorf("deleting the tag of another user: status = %d, want 403", w.Code)
+	}
+	if store.tags[johns.Id].Name != "work" {
+		t.Fatalf("the tag of john was changed: %+v", store.tags[johns.Id])
+	}
+
+	if w := serve(r, "DELETE", "/api/v1/tags/999", "", johnCookie); w.Code != http.StatusNotFound {
+		t.Errorf("deleting an unknown tag: status = %d, want 404", w.Code)
+	}
+	if w := serve(r, "DELETE", path, "", johnCookie); w.Code != http.StatusAccepted {
+		t.Errorf("deleting the own tag: status = %d, want 202", w.Code)
+	}
+}
+
+func TestMergeTag(t *testing.T) {
+	store := newFakeStore()
+	r := tagRouter(store.handler())
+	john, johnCookie := store.login("john")
+	jane, _ := store.login("jane")
+
+	from, _ := store.CreateTag(model.Tag{OwnerId: john.Id, Name: "job", Color: "#000000"})
+	into, _ := store.CreateTag(model.Tag{OwnerId: john.Id, Name: "work", Color: "#000000"})
+	janes, _ := store.CreateTag(model.Tag{OwnerId: jane.Id, Name: "work", Color: "#000000"})
+
+	path := "/api/v1/tags/" + strconv.Itoa(from.Id) + "/merge"
+	merge := func(id int) string { return `{"intoId":` + strconv.Itoa(id) + `}` }
+
+	if w := serve(r, "POST", path, merge(from.Id), johnCookie); w.Code != http.StatusBadRequest {
+		t.Errorf("merging into itself: status = %d, want 400", w.Code)
+	}
+	if w := serve(r, "POST", path, merge(janes.Id), johnCookie); w.Code != http.StatusForbidden {
+		t.Errorf("merging into the tag of another user: status = %d, want 403", w.Code)
+	}
+
+	w := serve(r, "POST", path, merge(into.Id), johnCookie)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body)
+	}
+	if _, ok := store.tags[from.Id]; ok {
+		t.Fatal("the merged tag was not deleted")
+	}
+}
+
+func TestTagsWithAccessToken(t *testing.T) {
+	store := newFakeStore()
+	r := tagRouter(store.handler())
+	user, _ := store.login("john")
+
+	store.accessTokens["todo_read"] = model.AccessToken{UserId: user.Id, Scopes: []string{model.ScopeRead}}
+	store.accessTokens["todo_write"] = model.AccessToken{UserId: user.Id, Scopes: []string{model.ScopeRead, model.ScopeTasksWrite}}
+
+	if w := serve(r, "GET", "/api/v1/tags", "", "Bearer todo_read"); w.Code != http.StatusOK {
+		t.Errorf("reading with the read scope: status = %d, want 200", w.Code)
+	}
+
+	body := `{"name":"urgent","color":"#ef4444"}`
+	if w := serve(r, "POST", "/api/v1/tags", body, "Bearer todo_read"); w.Code != http.StatusForbidden {
+		t.Errorf("creating with the read scope: status = %d, want 403", w.Code)
+	}
+	if w := serve(r, "POST", "/api/v1/tags", body, "Bearer todo_write"); w.Code != http.StatusCreated {
+		t.Errorf("creating with the tasks:write scope: status = %d, want 201", w.Code)
+	}
+}
@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/0l1v3rr/todo/app/model"
 	"github.com/0l1v3rr/todo/app/util"
@@ -11,18 +13,27 @@
 )
 
 // @Summary      Get list tasks
-// @Description  Returns all the tasks in the specified list
+// @Description  Returns the tasks in the specified list, a page at a time if a limit is specified.
+// @Description  The urls of the next and the previous pages are in the Link header.
 // @Tags         Task endpoints
 // @Produce      json
 // @Param 		 id path int true "list ID"
+// @Param 		 sort query string false "the order of the tasks" Enums(position, created, due, priority) default(position)
+// @Param 		 limit query int false "the maximum number of tasks" minimum(1) maximum(100)
+// @Param 		 cursor query string false "the cursor of the page from the Link header"
+// @Param 		 isDone query bool false "only the done or the open tasks"
+// @Param 		 createdAfter query string false "only the tasks created at or after this RFC 3339 time"
+// @Param 		 createdBefore query string false "only the tasks created before this RFC 3339 time"
+// @Param 		 title query string false "only the tasks with a title containing this text"
 // @Success      200  {array}   model.Task
-// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Header       200  {string}  Link "the urls of the next and the previous pages"
+// @Failure      400  {object}  util.Error "If the id, the sort, the filters or the page are not valid."
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list."
 // @Failure      404  {object}  util.Error "If the list with this id does not exist."
 // @Failure      500  {object}  util.Error "If there was a db error.."
 // @Router       /tasks/list/{id} [get]
-func GetTasksByListId(c *gin.Context) {
+func (h *Handler) GetTasksByListId(c *gin.Context) {
 	// parsing the listId parameter
 	listId, err := strconv.Atoi(c.Param("listId"))
 	if err != nil {
@@ -30,29 +41,161 @@ func GetTasksByListId(c *gin.Context) {
 		return
 	}
 
-	// checking whether the list exists
-	_, exists := model.ListExists(listId)
-	if !exists {
-		c.JSON(http.StatusNotFound, util.Error{Message: "List with this ID does not exist."})
+	// checking whether the sort is valid
+	sort := c.DefaultQuery("sort", "position")
+	if !model.IsValidTaskSort(sort) {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid sort."})
+		return
+	}
+
+	// parsing the page
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	// parsing the filters
+	filter := model.TaskFilter{Title: c.Query("title")}
+
+	if isDone := c.Query("isDone"); isDone != "" {
+		done, err := strconv.ParseBool(isDone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.Error{Message: "The isDone has to be true or false."})
+			return
+		}
+		filter.IsDone = &done
+	}
+
+	if filter.CreatedAfter, ok = bindTimeQuery(c, "createdAfter"); !ok {
+		return
+	}
+
+	if filter.CreatedBefore, ok = bindTimeQuery(c, "createdBefore"); !ok {
+		return
+	}
+
+	// checking whether the list exists and the user can view it
+	if _, ok := h.authorizeList(c, listId, model.RoleViewer); !ok {
+		return
+	}
+
+	// getting the tasks from the db
+	tasks, info, err := h.Tasks.GetTasks(listId, loggedInUser(c).Id, filter, sort, page)
+	if err == model.ErrInvalidCursor {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid cursor."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	setPageLinks(c, info)
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @Summary      Get tasks by tags
+// @Description  Returns the tasks that have every specified tag of the user from every list the user has access to
+// @Tags         Task endpoints
+// @Produce      json
+// @Param 		 tag query []string true "tag name" collectionFormat(multi)
+// @Success      200  {array}   model.Task
+// @Failure      400  {object}  util.Error "If no tag was specified."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks [get]
+func (h *Handler) GetTasksByTags(c *gin.Context) {
+	// getting the tags from the query
+	tags := c.QueryArray("tag")
+	if len(tags) == 0 {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify at least one tag."})
 		return
 	}
 
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// getting the tasks from the db
+	tasks, err := h.Tags.GetTasksByTags(user.Id, tags)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
 	}
 
-	// checking if the user has permission to view the list
-	listOwner := model.GetListOwnerId(listId)
-	if user.Id != listOwner {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to view this list."})
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @Summary      Get top priority tasks
+// @Description  Returns the highest priority open tasks from every list the user has access to
+// @Tags         Task endpoints
+// @Produce      json
+// @Param 		 limit query int false "the number of tasks" minimum(1) maximum(50) default(10)
+// @Success      200  {array}   model.Task
+// @Failure      400  {object}  util.Error "If the limit is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/top [get]
+func (h *Handler) GetTopPriorityTasks(c *gin.Context) {
+	// parsing the limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The limit has to be between 1 and 50."})
 		return
 	}
 
+	// getting the logged-in user
+	user := loggedInUser(c)
+
 	// getting the tasks from the db
-	tasks, err := model.GetTasks(listId)
+	tasks, err := h.Tasks.GetTopPriorityTasks(user.Id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @Summary      Get due tasks
+// @Description  Returns the open tasks that are overdue, due today or due this week from every list the user has access to
+// @Tags         Task endpoints
+// @Produce      json
+// @Param 		 period path string true "the period the tasks are due in" Enums(overdue, today, week)
+// @Param 		 tz query string false "the IANA time zone of the user" default(UTC)
+// @Success      200  {array}   model.Task
+// @Failure      400  {object}  util.Error "If the period or the time zone is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/due/{period} [get]
+func (h *Handler) GetDueTasks(c *gin.Context) {
+	// parsing the time zone
+	loc, err := util.LoadLocation(c.Query("tz"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid time zone."})
+		return
+	}
+
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// getting the tasks from the db based on the period
+	var tasks []model.Task
+	now := time.Now()
+
+	switch c.Param("period") {
+	case "overdue":
+		tasks, err = h.Tasks.GetOverdueTasks(user.Id, now)
+	case "today":
+		from := util.StartOfDay(now, loc)
+		tasks, err = h.Tasks.GetTasksDueBetween(user.Id, from, from.AddDate(0, 0, 1))
+	case "week":
+		from := util.StartOfWeek(now, loc)
+		tasks, err = h.Tasks.GetTasksDueBetween(user.Id, from, from.AddDate(0, 0, 7))
+	default:
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid period."})
+		return
+	}
+
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
@@ -71,31 +214,71 @@ func GetTasksByListId(c *gin.Context) {
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list the task is in."
 // @Router       /tasks/{url} [get]
-func GetTaskByUrl(c *gin.Context) {
-	// getting the url from the parameter
-	url := c.Param("url")
+func (h *Handler) GetTaskByUrl(c *gin.Context) {
+	// getting the task if the user can view the list it is in
+	task, ok := h.authorizeTaskUrl(c, c.Param("url"), model.RoleViewer)
+	if !ok {
+		return
+	}
 
-	task, err := model.GetTaskByUrl(url)
-	if err != nil {
-		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this URL does not exist."})
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary      Get task occurrences
+// @Description  Returns the next occurrences of the recurring task with the specified url
+// @Tags         Task endpoints
+// @Produce      json
+// @Param 		 url path string true "task URL"
+// @Param 		 count query int false "the number of occurrences" minimum(1) maximum(50) default(5)
+// @Param 		 tz query string false "the IANA time zone of the weekdays and the time of the day of the rule" default(UTC)
+// @Success      200  {array}   time.Time
+// @Failure      400  {object}  util.Error "If the count or the time zone is not valid or the task is not recurring."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user doesn't have permission to view the list the task is in."
+// @Failure      404  {object}  util.Error "If the task doesn't exist."
+// @Router       /tasks/{url}/occurrences [get]
+func (h *Handler) GetTaskOccurrences(c *gin.Context) {
+	// parsing the count parameter
+	count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+	if err != nil || count < 1 || count > 50 {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The count has to be between 1 and 50."})
 		return
 	}
 
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
+	// parsing the time zone
+	loc, err := util.LoadLocation(c.Query("tz"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid time zone."})
 		return
 	}
 
-	// checking if the user has permission to view the list the task is in
-	listOwner := model.GetListOwnerId(task.ListId)
-	if user.Id != listOwner {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to view this list."})
+	// getting the task if the user can view the list it is in
+	task, ok := h.authorizeTaskUrl(c, c.Param("url"), model.RoleViewer)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	// parsing the recurrence rule of the task
+	if task.Recurrence == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "This task is not recurring."})
+		return
+	}
+
+	rule, err := util.ParseRecurrence(task.Recurrence)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: err.Error()})
+		return
+	}
+
+	// the occurrences start from the due date, or from now if there is none
+	from := time.Now().In(loc)
+	if task.DueDate != nil {
+		from = task.DueDate.In(loc)
+	}
+
+	// the due date itself is the first occurrence
+	occurrences := append([]time.Time{from}, rule.Upcoming(from, count-1)...)
+	c.JSON(http.StatusOK, occurrences)
 }
 
 // @Summary      Change task status
@@ -103,13 +286,15 @@ func GetTaskByUrl(c *gin.Context) {
 // @Tags         Task endpoints
 // @Produce      json
 // @Param 		 id path int true "task ID"
+// @Param 		 completeItems query bool false "whether to complete the checklist items when the task is done"
+// @Param 		 tz query string false "the IANA time zone of the weekdays and the time of the day of the recurrence" default(UTC)
 // @Success      202  {object}  model.Task
-// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      400  {object}  util.Error "If the id or the time zone is not valid."
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      403  {object}  util.Error "If the user has no permission to do this."
 // @Failure      404  {object}  util.Error "If the task doesn't exist."
 // @Router       /tasks/{id} [patch]
-func ChangeTaskStatus(c *gin.Context) {
+func (h *Handler) ChangeTaskStatus(c *gin.Context) {
 	// parsing the id parameter
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -117,28 +302,21 @@ func ChangeTaskStatus(c *gin.Context) {
 		return
 	}
 
-	// checking if the task exists
-	existingTask, exists := model.TaskExists(id)
-	if !exists {
-		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID does not exist."})
-		return
-	}
-
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
+	// parsing the time zone of the next occurrence
+	loc, err := util.LoadLocation(c.Query("tz"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid time zone."})
 		return
 	}
 
-	// checking if the user has permission
-	if user.Id != existingTask.CreatedById {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to do this."})
+	// checking if the task exists and the user can edit the list it is in
+	if _, ok := h.authorizeTask(c, id, model.RoleEditor); !ok {
 		return
 	}
 
 	// changing the IsDone parameter
-	task, err := model.ChangeIsDone(id)
+	completeItems := c.Query("completeItems") == "true"
+	task, err := h.Tasks.ChangeIsDone(id, completeItems, loggedInUser(c).Id, loc)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
@@ -161,7 +339,7 @@ func ChangeTaskStatus(c *gin.Context) {
 // @Failure      404  {object}  util.Error "If the list with the specified ID does not exist."
 // @Failure      500  {object}  util.Error "If there was a db error."
 // @Router       /tasks/{id} [post]
-func CreateTask(c *gin.Context) {
+func (h *Handler) CreateTask(c *gin.Context) {
 	// binding the task from the body
 	var task model.Task
 
@@ -177,32 +355,32 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+	// checking if the list exists and the user can create tasks in it
+	if _, ok := h.authorizeList(c, task.ListId, model.RoleEditor); !ok {
 		return
 	}
 
-	// checking if the list exists
-	_, exists := model.ListExists(task.ListId)
-	if !exists {
-		c.JSON(http.StatusNotFound, util.Error{Message: "List with this ID does not exist."})
-		return
-	}
+	// getting the logged-in user
+	user := loggedInUser(c)
 
-	// checking if the user has permission to create task in the list
-	listOwner := model.GetListOwnerId(task.ListId)
-	if user.Id != listOwner {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to create in this list."})
+	// checking if the user owns the tags
+	if !h.Tags.OwnsTags(user.Id, task.Tags) {
+		c.JSON(http.StatusForbidden, util.Error{Message: "You can only use your own tags."})
 		return
 	}
 
 	// changing the CreatedById
-	task.CreatedById = user.Id
+	task.CreatedById = &user.Id
 
 	// creating the task
-	task, err = model.CreateTask(task)
+	task, err := h.Tasks.CreateTask(task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// linking the tags to the task
+	task.Tags, err = h.setTaskTags(task.Id, user.Id, task.Tags)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
@@ -225,7 +403,7 @@ func CreateTask(c *gin.Context) {
 // @Failure      403  {object}  util.Error "If the user has no permission to do this."
 // @Failure      500  {object}  util.Error "If there was a db error."
 // @Router       /tasks/{id} [put]
-func EditTask(c *gin.Context) {
+func (h *Handler) EditTask(c *gin.Context) {
 	// parsing the id parameter
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -233,23 +411,9 @@ func EditTask(c *gin.Context) {
 		return
 	}
 
-	// checking if the task exists
-	existingTask, exists := model.TaskExists(id)
-	if !exists {
-		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID does not exist."})
-		return
-	}
-
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
-		return
-	}
-
-	// checking if the user has permission
-	if user.Id != existingTask.CreatedById {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to do this."})
+	// checking if the task exists and the user can edit the list it is in
+	existingTask, ok := h.authorizeTask(c, id, model.RoleEditor)
+	if !ok {
 		return
 	}
 
@@ -268,12 +432,31 @@ func EditTask(c *gin.Context) {
 		return
 	}
 
-	// changing the task id and the CreatedById
+	// checking if the user owns the tags
+	user := loggedInUser(c)
+	if !h.Tags.OwnsTags(user.Id, task.Tags) {
+		c.JSON(http.StatusForbidden, util.Error{Message: "You can only use your own tags."})
+		return
+	}
+
+	// keeping the identity of the task, it can only be
+	// moved to another list with the move endpoint
 	task.Id = id
-	task.CreatedById = user.Id
+	task.ListId = existingTask.ListId
+	task.Url = existingTask.Url
+	task.CreatedById = existingTask.CreatedById
+	task.CreatedAt = existingTask.CreatedAt
+	task.Position = existingTask.Position
 
 	// saving the task in the db
-	saved, err := model.EditTask(task)
+	saved, err := h.Tasks.EditTask(task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// linking the tags to the task
+	saved.Tags, err = h.setTaskTags(saved.Id, user.Id, task.Tags)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
@@ -283,18 +466,81 @@ func EditTask(c *gin.Context) {
 	c.JSON(http.StatusAccepted, saved)
 }
 
-// @Summary      Delete task
-// @Description  Deletes the task
+// @Summary      Move tasks
+// @Description  Moves the tasks to the top of another list, the tags of the users who can't access that list are removed
 // @Tags         Task endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 transfer body model.TransferTasks true "The tasks and the destination list"
+// @Success      202  {array}   model.Task
+// @Failure      400  {object}  util.Error "If the body is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to edit the source or the destination list."
+// @Failure      404  {object}  util.Error "If a task or the destination list does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/move [post]
+func (h *Handler) MoveTasks(c *gin.Context) {
+	// binding and checking the transfer, the source lists have to be editable
+	transfer, user, ok := h.bindTransfer(c, model.RoleEditor)
+	if !ok {
+		return
+	}
+
+	// moving the tasks
+	tasks, err := h.Tasks.MoveTasks(transfer.TaskIds, transfer.ListId, transfer.RegenerateUrl, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, tasks)
+}
+
+// @Summary      Copy tasks
+// @Description  Copies the tasks with their checklists and the tags of the user to the top of another list
+// @Tags         Task endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 transfer body model.TransferTasks true "The tasks and the destination list"
+// @Success      201  {array}   model.Task
+// @Failure      400  {object}  util.Error "If the body is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to view the source or edit the destination list."
+// @Failure      404  {object}  util.Error "If a task or the destination list does not exist."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/copy [post]
+func (h *Handler) CopyTasks(c *gin.Context) {
+	// binding and checking the transfer, the source lists only have to be viewable
+	transfer, user, ok := h.bindTransfer(c, model.RoleViewer)
+	if !ok {
+		return
+	}
+
+	// copying the tasks
+	tasks, err := h.Tasks.CopyTasks(transfer.TaskIds, transfer.ListId, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tasks)
+}
+
+// @Summary      Reorder task
+// @Description  Moves the task right before or after another task of the same list
+// @Tags         Task endpoints
+// @Accept       json
+// @Produce      json
+// @Param 		 reorder body model.Reorder true "The task to move before or after"
 // @Param 		 id path int true "task ID"
 // @Success      202  {object}  model.Task
-// @Failure      400  {object}  util.Error "If the id is not valid."
-// @Failure      404  {object}  util.Error "If the task does not exist."
+// @Failure      400  {object}  util.Error "If the id or the target is not valid."
 // @Failure      401  {object}  util.Error "If the user is not logged in."
 // @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the task does not exist."
 // @Failure      500  {object}  util.Error "If there was a db error."
-// @Router       /tasks/{id} [delete]
-func DeleteTask(c *gin.Context) {
+// @Router       /tasks/{id}/position [put]
+func (h *Handler) ReorderTask(c *gin.Context) {
 	// parsing the id parameter
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -302,28 +548,58 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	// checking if the task exists
-	existingTask, exists := model.TaskExists(id)
-	if !exists {
-		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID does not exist."})
+	// binding the target from the body
+	targetId, after, ok := bindReorder(c)
+	if !ok {
 		return
 	}
 
-	// checking if the user is logged in
-	user, err := model.GetLoggedInUser(*c)
+	// checking if the task exists and the user can edit the list it is in
+	if _, ok := h.authorizeTask(c, id, model.RoleEditor); !ok {
+		return
+	}
+
+	// moving the task
+	task, err := h.Tasks.MoveTask(id, targetId, after)
+	if err == model.ErrTargetNotFound {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The target task has to be in the same list."})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, util.Error{Message: "You are not logged in."})
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
 	}
 
-	// checking if the user has permission
-	if user.Id != existingTask.CreatedById {
-		c.JSON(http.StatusForbidden, util.Error{Message: "You do not have permission to do this."})
+	// success
+	c.JSON(http.StatusAccepted, task)
+}
+
+// @Summary      Delete task
+// @Description  Moves the task to the trash
+// @Tags         Task endpoints
+// @Param 		 id path int true "task ID"
+// @Success      202  {object}  model.Task
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      404  {object}  util.Error "If the task does not exist."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tasks/{id} [delete]
+func (h *Handler) DeleteTask(c *gin.Context) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return
+	}
+
+	// checking if the task exists and the user can edit the list it is in
+	if _, ok := h.authorizeTask(c, id, model.RoleEditor); !ok {
 		return
 	}
 
 	// deleting the task
-	err = model.DeleteTask(id)
+	err = h.Tasks.DeleteTask(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
 		return
@@ -332,3 +608,57 @@ func DeleteTask(c *gin.Context) {
 	// success
 	c.Status(http.StatusAccepted)
 }
+
+// links the tags of the user to the task and returns the tags of the user on the task,
+// nil tags mean that the tags of the task should not be changed
+func (h *Handler) setTaskTags(taskId int, userId int, tags []model.Tag) ([]model.Tag, error) {
+	if tags != nil {
+		if err := h.Tags.SetTaskTags(taskId, userId, tags); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.Tags.GetTaskTags(taskId, userId)
+}
+
+// binds the transfer from the body and checks whether the logged-in user has the role
+// in the lists of the tasks and can put them into the destination list,
+// otherwise it writes the error response and returns false
+func (h *Handler) bindTransfer(c *gin.Context, role string) (model.TransferTasks, model.User, bool) {
+	// binding the transfer from the body
+	var transfer model.TransferTasks
+
+	if err := c.ShouldBindBodyWith(&transfer, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide valid tasks and a list."})
+		return model.TransferTasks{}, model.User{}, false
+	}
+
+	// validating the transfer
+	valid, msg := transfer.Validate()
+	if !valid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return model.TransferTasks{}, model.User{}, false
+	}
+
+	// checking if the destination list exists and the user can create tasks in it
+	if _, ok := h.authorizeList(c, transfer.ListId, model.RoleEditor); !ok {
+		return model.TransferTasks{}, model.User{}, false
+	}
+
+	// checking every task and whether the user has the role in its list
+	user := loggedInUser(c)
+	for _, id := range transfer.TaskIds {
+		task, exists := h.Tasks.TaskExists(id)
+		if !exists {
+			c.JSON(http.StatusNotFound, util.Error{Message: fmt.Sprintf("Task with the ID %d does not exist.", id)})
+			return model.TransferTasks{}, model.User{}, false
+		}
+
+		if !h.Lists.HasRole(task.ListId, user.Id, role) {
+			c.JSON(http.StatusForbidden, util.Error{Message: fmt.Sprintf("You do not have permission to do this with the task %d.", id)})
+			return model.TransferTasks{}, model.User{}, false
+		}
+	}
+
+	return transfer, user, true
+}
@@ -0,0 +1,441 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+// the task routes of main
+func taskRouter(h *Handler) *gin.Engine {
+	r := gin.New()
+	api := r.Group("/api/v1", h.Authenticate)
+	api.GET("/tasks", h.GetTasksByTags)
+	api.GET("/tasks/list/:listId", h.GetTasksByListId)
+	api.GET("/tasks/top", h.GetTopPriorityTasks)
+	api.GET("/tasks/due/:period", h.GetDueTasks)
+	api.GET("/tasks/:url", h.GetTaskByUrl)
+	api.GET("/tasks/:url/occurrences", h.GetTaskOccurrences)
+	api.POST("/tasks", h.CreateTask)
+	api.POST("/tasks/move", h.MoveTasks)
+	api.POST("/tasks/copy", h.CopyTasks)
+	api.PATCH("/tasks/:id", h.ChangeTaskStatus)
+	api.PUT("/tasks/:id", h.EditTask)
+	api.PUT("/tasks/:id/position", h.ReorderTask)
+	api.DELETE("/tasks/:id", h.DeleteTask)
+
+	return r
+}
+
+func taskTitles(t *testing.T, body []byte) []string {
+	t.Helper()
+
+	var tasks []model.Task
+	if err := json.Unmarshal(body, &tasks); err != nil {
+		t.Fatalf("%s: %s", err, body)
+	}
+
+	titles := []string{}
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+
+	return titles
+}
+
+func TestCreateTask(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	viewer, viewerCookie := store.login("viewer")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	store.CreateMember(model.Member{ListId: list.Id, UserId: viewer.Id, Role: model.RoleViewer})
+	tag, _ := store.CreateTag(model.Tag{OwnerId: owner.Id, Name: "home", Color: "#ffffff"})
+	othersTag, _ := store.CreateTag(model.Tag{OwnerId: viewer.Id, Name: "mine", Color: "#000000"})
+
+	listId := strconv.Itoa(list.Id)
+	body := `{"listId":` + listId + `,"title":"Wash up","createdById":99,"tags":[{"id":` + strconv.Itoa(tag.Id) + `}]}`
+
+	w := serve(r, "POST", "/api/v1/tasks", body, ownerCookie)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+
+	var created model.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.CreatedById == nil || *created.CreatedById != owner.Id {
+		t.Errorf("created = %+v, want a task created by %d", created, owner.Id)
+	}
+	if len(created.Tags) != 1 || created.Tags[0].Name != "home" {
+		t.Errorf("tags = %+v, want the home tag", created.Tags)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		cookie string
+		status int
+	}{
+		{"short title", `{"listId":` + listId + `,"title":"ab"}`, ownerCookie, http.StatusBadRequest},
+		{"invalid priority", `{"listId":` + listId + `,"title":"Wash up","priority":5}`, ownerCookie, http.StatusBadRequest},
+		{"invalid recurrence", `{"listId":` + listId + `,"title":"Wash up","recurrence":"FREQ=HOURLY"}`, ownerCookie, http.StatusBadRequest},
+		{"start after due", `{"listId":` + listId + `,"title":"Wash up","startDate":"2022-08-02T00:00:00Z","dueDate":"2022-08-01T00:00:00Z"}`, ownerCookie, http.StatusBadRequest},
+		{"unknown list", `{"listId":999,"title":"Wash up"}`, ownerCookie, http.StatusNotFound},
+		{"as a viewer", `{"listId":` + listId + `,"title":"Wash up"}`, viewerCookie, http.StatusForbidden},
+		{"tag of another user", `{"listId":` + listId + `,"title":"Wash up","tags":[{"id":` + strconv.Itoa(othersTag.Id) + `}]}`, ownerCookie, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		if w := serve(r, "POST", "/api/v1/tasks", test.body, test.cookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d: %s", test.name, w.Code, test.status, w.Body)
+		}
+	}
+
+	if len(store.tasks) != 1 {
+		t.Errorf("%d tasks were created, want 1", len(store.tasks))
+	}
+}
+
+func TestGetTasksByListId(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	_, strangerCookie := store.login("stranger")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	for i, title := range []string{"Cook", "Shop", "Clean"} {
+		task, _ := store.CreateTask(model.Task{ListId: list.Id, CreatedById: &owner.Id, Title: title, Priority: i})
+		task.CreatedAt = time.Date(2022, 7, 20+i, 0, 0, 0, 0, time.UTC)
+		task.IsDone = title == "Shop"
+		store.EditTask(task)
+	}
+
+	path := "/api/v1/tasks/list/" + strconv.Itoa(list.Id)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Clean", "Shop", "Cook"}},
+		{"?sort=created", []string{"Cook", "Shop", "Clean"}},
+		{"?sort=priority", []string{"Clean", "Shop", "Cook"}},
+		{"?isDone=false&sort=created", []string{"Cook", "Clean"}},
+		{"?createdAfter=2022-07-21T00:00:00Z&sort=created", []string{"Shop", "Clean"}},
+		{"?createdBefore=2022-07-21T00:00:00Z", []string{"Cook"}},
+		{"?title=sh", []string{"Shop"}},
+	}
+
+	for _, test := range tests {
+		w := serve(r, "GET", path+test.query, "", ownerCookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200: %s", test.query, w.Code, w.Body)
+		}
+		if titles := taskTitles(t, w.Body.Bytes()); !reflect.DeepEqual(titles, test.want) {
+			t.Errorf("%s: tasks = %v, want %v", test.query, titles, test.want)
+		}
+	}
+
+	invalid := []struct {
+		name   string
+		path   string
+		cookie string
+		status int
+	}{
+		{"invalid id", "/api/v1/tasks/list/chores", ownerCookie, http.StatusBadRequest},
+		{"invalid sort", path + "?sort=title", ownerCookie, http.StatusBadRequest},
+		{"invalid isDone", path + "?isDone=maybe", ownerCookie, http.StatusBadRequest},
+		{"invalid time", path + "?createdAfter=yesterday", ownerCookie, http.StatusBadRequest},
+		{"invalid cursor", path + "?limit=1&cursor=nope", ownerCookie, http.StatusBadRequest},
+		{"unknown list", "/api/v1/tasks/list/999", ownerCookie, http.StatusNotFound},
+		{"list of another user", path, strangerCookie, http.StatusForbidden},
+	}
+
+	for _, test := range invalid {
+		if w := serve(r, "GET", test.path, "", test.cookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, w.Code, test.status)
+		}
+	}
+}
+
+func TestEditTask(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	viewer, viewerCookie := store.login("viewer")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	other, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Other"})
+	store.CreateMember(model.Member{ListId: list.Id, UserId: viewer.Id, Role: model.RoleViewer})
+	task, _ := store.CreateTask(model.Task{ListId: list.Id, CreatedById: &owner.Id, Title: "Cook"})
+
+	// the viewer's tag stays on the task when the owner changes theirs
+	viewersTag, _ := store.CreateTag(model.Tag{OwnerId: viewer.Id, Name: "watch", Color: "#000000"})
+	store.SetTaskTags(task.Id, viewer.Id, []model.Tag{viewersTag})
+
+	path := "/api/v1/tasks/" + strconv.Itoa(task.Id)
+	body := `{"title":"Cook dinner","listId":` + strconv.Itoa(other.Id) + `,"url":"stolen","priority":3,"tags":[]}`
+
+	if w := serve(r, "PUT", path, body, viewerCookie); w.Code != http.StatusForbidden {
+		t.Errorf("editing as a viewer: status = %d, want 403", w.Code)
+	}
+	if w := serve(r, "PUT", "/api/v1/tasks/999", body, ownerCookie); w.Code != http.StatusNotFound {
+		t.Errorf("editing an unknown task: status = %d, want 404", w.Code)
+	}
+	if w := serve(r, "PUT", path, `{"title":"ab"}`, ownerCookie); w.Code != http.StatusBadRequest {
+		t.Errorf("editing with a short title: status = %d, want 400", w.Code)
+	}
+
+	w := serve(r, "PUT", path, body, ownerCookie)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body)
+	}
+
+	// the task keeps its list and its url
+	edited := store.tasks[task.Id]
+	if edited.Title != "Cook dinner" || edited.Priority != 3 || edited.ListId != list.Id || edited.Url != task.Url {
+		t.Errorf("edited task = %+v", edited)
+	}
+	if tags, _ := store.GetTaskTags(task.Id, viewer.Id); len(tags) != 1 {
+		t.Errorf("tags of the viewer = %+v, want them kept", tags)
+	}
+}
+
+func TestChangeTaskStatusAndDelete(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, _ := store.login("owner")
+	editor, editorCookie := store.login("editor")
+	viewer, viewerCookie := store.login("viewer")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	store.CreateMember(model.Member{ListId: list.Id, UserId: editor.Id, Role: model.RoleEditor})
+	store.CreateMember(model.Member{ListId: list.Id, UserId: viewer.Id, Role: model.RoleViewer})
+	task, _ := store.CreateTask(model.Task{ListId: list.Id, CreatedById: &owner.Id, Title: "Cook"})
+
+	path := "/api/v1/tasks/" + strconv.Itoa(task.Id)
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		cookie string
+		status int
+	}{
+		{"marking done as a viewer", "PATCH", path, viewerCookie, http.StatusForbidden},
+		{"marking done with an invalid time zone", "PATCH", path + "?tz=Mars/Olympus", editorCookie, http.StatusBadRequest},
+		{"marking done as an editor", "PATCH", path + "?tz=Europe/Budapest", editorCookie, http.StatusAccepted},
+		{"deleting as a viewer", "DELETE", path, viewerCookie, http.StatusForbidden},
+		{"deleting as an editor", "DELETE", path, editorCookie, http.StatusAccepted},
+		{"marking done a deleted task", "PATCH", path, editorCookie, http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		if w := serve(r, step.method, step.path, "", step.cookie); w.Code != step.status {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.status, w.Body)
+		}
+
+		if step.name == "marking done as an editor" && !store.tasks[task.Id].IsDone {
+			t.Error("the task was not marked done")
+		}
+	}
+}
+
+func TestReorderTask(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	other, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Other"})
+	a, _ := store.CreateTask(model.Task{ListId: list.Id, Title: "A"})
+	b, _ := store.CreateTask(model.Task{ListId: list.Id, Title: "B"})
+	c, _ := store.CreateTask(model.Task{ListId: list.Id, Title: "C"})
+	elsewhere, _ := store.CreateTask(model.Task{ListId: other.Id, Title: "Elsewhere"})
+
+	reorder := func(id int, body string) int {
+		return serve(r, "PUT", "/api/v1/tasks/"+strconv.Itoa(id)+"/position", body, ownerCookie).Code
+	}
+
+	if status := reorder(a.Id, `{"beforeId":`+strconv.Itoa(c.Id)+`}`); status != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", status)
+	}
+
+	w := serve(r, "GET", "/api/v1/tasks/list/"+strconv.Itoa(list.Id), "", ownerCookie)
+	if titles := taskTitles(t, w.Body.Bytes()); !reflect.DeepEqual(titles, []string{"A", "C", "B"}) {
+		t.Errorf("tasks = %v, want [A C B]", titles)
+	}
+
+	tests := []struct {
+		name   string
+		id     int
+		body   string
+		status int
+	}{
+		{"target in another list", a.Id, `{"afterId":` + strconv.Itoa(elsewhere.Id) + `}`, http.StatusBadRequest},
+		{"no target", a.Id, `{}`, http.StatusBadRequest},
+		{"unknown task", 999, `{"afterId":` + strconv.Itoa(b.Id) + `}`, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		if status := reorder(test.id, test.body); status != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, status, test.status)
+		}
+	}
+}
+
+func TestMoveAndCopyTasks(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	viewer, viewerCookie := store.login("viewer")
+
+	source, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Source"})
+	target, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Target"})
+	viewersList, _ := store.CreateList(model.List{OwnerId: viewer.Id, Name: "Viewers"})
+	store.CreateMember(model.Member{ListId: source.Id, UserId: viewer.Id, Role: model.RoleViewer})
+
+	task, _ := store.CreateTask(model.Task{ListId: source.Id, Title: "Cook"})
+	other, _ := store.CreateTask(model.Task{ListId: source.Id, Title: "Shop"})
+
+	transfer := func(ids []int, listId int) string {
+		body, _ := json.Marshal(model.TransferTasks{TaskIds: ids, ListId: listId})
+		return string(body)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		cookie string
+		status int
+	}{
+		{"no tasks", "/api/v1/tasks/move", transfer([]int{}, target.Id), ownerCookie, http.StatusBadRequest},
+		{"the same task twice", "/api/v1/tasks/move", transfer([]int{task.Id, task.Id}, target.Id), ownerCookie, http.StatusBadRequest},
+		{"unknown task", "/api/v1/tasks/move", transfer([]int{999}, target.Id), ownerCookie, http.StatusNotFound},
+		{"unknown list", "/api/v1/tasks/move", transfer([]int{task.Id}, 999), ownerCookie, http.StatusNotFound},
+		{"moving as a viewer", "/api/v1/tasks/move", transfer([]int{task.Id}, viewersList.Id), viewerCookie, http.StatusForbidden},
+		{"copying into a list of another user", "/api/v1/tasks/copy", transfer([]int{task.Id}, target.Id), viewerCookie, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		if w := serve(r, "POST", test.path, test.body, test.cookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d: %s", test.name, w.Code, test.status, w.Body)
+		}
+	}
+
+	// a viewer can copy the tasks into their own list
+	w := serve(r, "POST", "/api/v1/tasks/copy", transfer([]int{task.Id}, viewersList.Id), viewerCookie)
+	var copies []model.Task
+	json.Unmarshal(w.Body.Bytes(), &copies)
+	if w.Code != http.StatusCreated || len(copies) != 1 || copies[0].ListId != viewersList.Id || *copies[0].CreatedById != viewer.Id {
+		t.Fatalf("copy = %d %s", w.Code, w.Body)
+	}
+	if store.tasks[task.Id].ListId != source.Id {
+		t.Error("the copied task was moved")
+	}
+
+	// the owner moves the tasks in the given order
+	w = serve(r, "POST", "/api/v1/tasks/move", transfer([]int{other.Id, task.Id}, target.Id), ownerCookie)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body)
+	}
+
+	w = serve(r, "GET", "/api/v1/tasks/list/"+strconv.Itoa(target.Id), "", ownerCookie)
+	if titles := taskTitles(t, w.Body.Bytes()); !reflect.DeepEqual(titles, []string{"Shop", "Cook"}) {
+		t.Errorf("tasks of the target = %v, want [Shop Cook]", titles)
+	}
+}
+
+func TestTasksAcrossLists(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+	_, strangerCookie := store.login("stranger")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	tag, _ := store.CreateTag(model.Tag{OwnerId: owner.Id, Name: "home", Color: "#ffffff"})
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	nextYear := time.Now().AddDate(1, 0, 0)
+	overdue, _ := store.CreateTask(model.Task{ListId: list.Id, Title: "Overdue", DueDate: &yesterday, Priority: model.PriorityLow})
+	store.CreateTask(model.Task{ListId: list.Id, Title: "Later", DueDate: &nextYear, Priority: model.PriorityUrgent})
+	store.CreateTask(model.Task{ListId: list.Id, Title: "Someday"})
+	store.SetTaskTags(overdue.Id, owner.Id, []model.Tag{tag})
+
+	tests := []struct {
+		path   string
+		cookie string
+		want   []string
+	}{
+		{"/api/v1/tasks/top", ownerCookie, []string{"Later", "Overdue"}},
+		{"/api/v1/tasks/top?limit=1", ownerCookie, []string{"Later"}},
+		{"/api/v1/tasks/due/overdue", ownerCookie, []string{"Overdue"}},
+		{"/api/v1/tasks?tag=home", ownerCookie, []string{"Overdue"}},
+		{"/api/v1/tasks/top", strangerCookie, []string{}},
+		{"/api/v1/tasks/due/overdue", strangerCookie, []string{}},
+	}
+
+	for _, test := range tests {
+		w := serve(r, "GET", test.path, "", test.cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200: %s", test.path, w.Code, w.Body)
+		}
+		if titles := taskTitles(t, w.Body.Bytes()); !reflect.DeepEqual(titles, test.want) {
+			t.Errorf("%s: tasks = %v, want %v", test.path, titles, test.want)
+		}
+	}
+
+	invalid := []string{"/api/v1/tasks/top?limit=51", "/api/v1/tasks/due/month", "/api/v1/tasks/due/today?tz=Mars/Olympus", "/api/v1/tasks"}
+	for _, path := range invalid {
+		if w := serve(r, "GET", path, "", ownerCookie); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, w.Code)
+		}
+	}
+
+	// the task by url is only shown to the users of the list
+	if w := serve(r, "GET", "/api/v1/tasks/"+overdue.Url, "", strangerCookie); w.Code != http.StatusForbidden {
+		t.Errorf("the task of another user: status = %d, want 403", w.Code)
+	}
+	if w := serve(r, "GET", "/api/v1/tasks/unknown", "", ownerCookie); w.Code != http.StatusNotFound {
+		t.Errorf("an unknown task: status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetTaskOccurrences(t *testing.T) {
+	store := newFakeStore()
+	r := taskRouter(store.handler())
+	owner, ownerCookie := store.login("owner")
+
+	list, _ := store.CreateList(model.List{OwnerId: owner.Id, Name: "Chores"})
+	due := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	weekly, _ := store.CreateTask(model.Task{ListId: list.Id, Title: "Weekly", DueDate: &due, Recurrence: "FREQ=WEEKLY"})
+	once, _ := store.CreateTask(model.Task{ListId: list.Id, Title: "Once"})
+
+	w := serve(r, "GET", "/api/v1/tasks/"+weekly.Url+"/occurrences?count=3", "", ownerCookie)
+	var occurrences []time.Time
+	json.Unmarshal(w.Body.Bytes(), &occurrences)
+
+	want := []time.Time{due, due.AddDate(0, 0, 7), due.AddDate(0, 0, 14)}
+	if w.Code != http.StatusOK || len(occurrences) != len(want) {
+		t.Fatalf("occurrences = %d %s", w.Code, w.Body)
+	}
+	for i := range want {
+		if !occurrences[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %s, want %s", i, occurrences[i], want[i])
+		}
+	}
+
+	if w := serve(r, "GET", "/api/v1/tasks/"+once.Url+"/occurrences", "", ownerCookie); w.Code != http.StatusBadRequest {
+		t.Errorf("a task that is not recurring: status = %d, want 400", w.Code)
+	}
+	if w := serve(r, "GET", "/api/v1/tasks/"+weekly.Url+"/occurrences?count=0", "", ownerCookie); w.Code != http.StatusBadRequest {
+		t.Errorf("an invalid count: status = %d, want 400", w.Code)
+	}
+}
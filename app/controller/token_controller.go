@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// @Summary      Get access tokens
+// @Description  Returns the personal access tokens of the logged-in user, the newest first.
+// @Tags         Token endpoints
+// @Produce      json
+// @Success      200  {array}   model.AccessToken
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tokens [get]
+func (h *Handler) GetAccessTokens(c *gin.Context) {
+	// getting the logged-in user, this can't be done with an access token
+	user, _, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// getting the tokens from the db
+	tokens, err := h.AccessTokens.GetAccessTokens(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary      Create access token
+// @Description  Creates a personal access token, which can be sent in the Authorization header as a Bearer token.
+// @Description  Every token can read, the tasks:write and lists:write scopes allow changing the tasks and tags, or the lists and members.
+// @Description  The token is only returned in this response.
+// @Tags         Token endpoints
+// @Accept       json
+// @Produce      json
+// @Param        token body model.NewAccessToken true "Token to create"
+// @Success      201  {object}  model.AccessToken
+// @Failure      400  {object}  util.Error "If the token is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tokens [post]
+func (h *Handler) CreateAccessToken(c *gin.Context) {
+	// binding the token from the body
+	var body model.NewAccessToken
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid token."})
+		return
+	}
+
+	// validating the token
+	isValid, msg := body.Validate()
+	if !isValid {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// getting the logged-in user, this can't be done with an access token
+	user, _, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// creating the token
+	token, err := h.AccessTokens.CreateAccessToken(user.Id, body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// @Summary      Delete access token
+// @Description  Deletes a personal access token of the logged-in user, so it can't be used anymore.
+// @Tags         Token endpoints
+// @Produce      json
+// @Param        id path int true "Token ID"
+// @Success      200  {object}  util.Success "If the token has been deleted."
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      404  {object}  util.Error "If the user has no token with this id."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /tokens/{id} [delete]
+func (h *Handler) DeleteAccessToken(c *gin.Context) {
+	// getting the id from the url
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid id."})
+		return
+	}
+
+	// getting the logged-in user, this can't be done with an access token
+	user, _, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// deleting the token
+	deleted, err := h.AccessTokens.DeleteAccessToken(user.Id, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Token not found."})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "The token has been deleted."})
+}
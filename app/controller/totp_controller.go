@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/ratelimit"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// the name of the app in the authenticator apps
+	totpIssuer = "ToDo"
+
+	// how long the user has to enter the code after the password
+	mfaTTL = 5 * time.Minute
+)
+
+// @Summary      Enroll 2FA
+// @Description  Creates a new secret for an authenticator app and returns it with its otpauth uri.
+// @Description  Two-factor authentication is only enabled after the secret is confirmed with a code.
+// @Tags         User endpoints
+// @Produce      json
+// @Success      200  {object}  model.TotpSetup
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      409  {object}  util.Error "If 2FA is already enabled."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /user/2fa [post]
+func (h *Handler) EnrollTotp(c *gin.Context) {
+	// getting the logged-in user, this can't be done with an access token
+	user, _, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// the secret can't be replaced while it's used
+	if user.TotpEnabled {
+		c.JSON(http.StatusConflict, util.Error{Message: "Two-factor authentication is already enabled."})
+		return
+	}
+
+	secret, err := h.Users.StartTotp(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TotpSetup{
+		Secret: secret,
+		Uri:    util.TotpUri(totpIssuer, user.Email, secret),
+	})
+}
+
+// @Summary      Confirm 2FA
+// @Description  Enables two-factor authentication with a code of the authenticator app, and returns the recovery codes.
+// @Description  Every recovery code can be used once instead of a code, and they are only returned in this response.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        code body model.TotpCode true "Code of the authenticator app"
+// @Success      200  {object}  model.RecoveryCodes
+// @Failure      400  {object}  util.Error "If the code is not valid, or 2FA has not been enrolled."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      409  {object}  util.Error "If 2FA is already enabled."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /user/2fa/confirm [post]
+func (h *Handler) ConfirmTotp(c *gin.Context) {
+	// binding the code from the body
+	var body model.TotpCode
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid code."})
+		return
+	}
+
+	// getting the logged-in user, this can't be done with an access token
+	user, _, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	if user.TotpEnabled {
+		c.JSON(http.StatusConflict, util.Error{Message: "Two-factor authentication is already enabled."})
+		return
+	}
+
+	// enabling 2fa
+	codes, err := h.Users.EnableTotp(user, body.Code)
+	if err == model.ErrInvalidCode {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "The code is invalid."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.RecoveryCodes{RecoveryCodes: codes})
+}
+
+// @Summary      Disable 2FA
+// @Description  Disables two-factor authentication and deletes the recovery codes.
+// @Description  The password is required, or a code if the user has no password because it was created with an OIDC provider.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        confirm body model.TotpDisable true "Password of the user"
+// @Success      200  {object}  util.Success "If 2FA has been disabled."
+// @Failure      400  {object}  util.Error "If 2FA is not enabled."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the password or the code is incorrect."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /user/2fa [delete]
+func (h *Handler) DisableTotp(c *gin.Context) {
+	// binding the password from the body
+	var body model.TotpDisable
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide your password."})
+		return
+	}
+
+	// getting the logged-in user, this can't be done with an access token
+	user, _, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	if !user.TotpEnabled {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Two-factor authentication is not enabled."})
+		return
+	}
+
+	// checking the password, or the code if there's no password
+	if user.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)); err != nil {
+			c.JSON(http.StatusForbidden, util.Error{Message: "Incorrect password."})
+			return
+		}
+	} else if ok, err := h.Users.VerifySecondFactor(user, body.Code); err != nil || !ok {
+		c.JSON(http.StatusForbidden, util.Error{Message: "The code is invalid."})
+		return
+	}
+
+	if err := h.Users.DisableTotp(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "Two-factor authentication has been disabled."})
+}
+
+// @Summary      Login with 2FA
+// @Description  Finishes the login of a user with 2FA, with a code of the authenticator app or a recovery code.
+// @Description  The mfaToken is returned by the login endpoint, and it's invalidated after 5 wrong codes.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        login body model.LoginTotp true "The token of the login and the code"
+// @Success      200  {object}  util.Success "If the login was successful."
+// @Failure      400  {object}  util.Error "If the token is invalid or has expired."
+// @Failure      403  {object}  util.Error "If the code is invalid."
+// @Failure      429  {object}  util.Error "If there were too many attempts. The Retry-After header contains the seconds to wait."
+// @Failure      500  {object}  util.Error "If there was a server error while logging in."
+// @Router       /login/2fa [post]
+func (h *Handler) LoginTotp(c *gin.Context) {
+	// binding the token and the code from the body
+	var body model.LoginTotp
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil || body.MfaToken == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid token and code."})
+		return
+	}
+
+	// checking the token of the first step
+	token, err := h.Tokens.CheckToken(body.MfaToken, model.TokenMfa)
+	if err == model.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Your login has expired. Please log in again."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+
+	user, err := h.Users.GetUserById(token.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+
+	// the wrong codes count towards the lockout of the account
+	account := loginAccountKey(user.Email)
+	if wait, _ := ratelimit.Backend.LockedFor(account, time.Now()); wait > 0 {
+		ratelimit.Reject(c, wait, "Too many failed login attempts. Please try again later.")
+		return
+	}
+
+	// checking the code
+	ok, err := h.Users.VerifySecondFactor(user, body.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+	if !ok {
+		h.Tokens.FailToken(token.Id)
+
+		wait, err := ratelimit.Backend.Fail(account, loginLockout, time.Now())
+		if err == nil && wait > 0 {
+			ratelimit.Reject(c, wait, "Too many failed login attempts. Please try again later.")
+			return
+		}
+
+		c.JSON(http.StatusForbidden, util.Error{Message: "The code is invalid."})
+		return
+	}
+
+	// the token can only be used once
+	if _, err := h.Tokens.UseToken(body.MfaToken, model.TokenMfa); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Your login has expired. Please log in again."})
+		return
+	}
+
+	// logging in the user
+	if err := h.startSession(c, user); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to log in."})
+		return
+	}
+
+	ratelimit.Backend.Reset(account)
+
+	c.JSON(http.StatusOK, util.Success{Message: "Successful login!"})
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get trash
+// @Description  Returns the lists the user deleted and the tasks deleted from the lists the user can edit, the most recently deleted first.
+// @Description  The tasks deleted together with their list are restored with the list, so they are not returned.
+// @Description  Everything is deleted permanently at purgeAt.
+// @Tags         Trash endpoints
+// @Produce      json
+// @Success      200  {array}   model.TrashItem
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	// getting the trash of the logged-in user
+	items, err := h.Trash.GetTrash(loggedInUser(c).Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary      Restore task
+// @Description  Restores the task from the trash
+// @Tags         Trash endpoints
+// @Produce      json
+// @Param 		 id path int true "task ID"
+// @Success      202  {object}  model.Task
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the task does not exist or is not in the trash."
+// @Failure      409  {object}  util.Error "If the list of the task is deleted."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /trash/tasks/{id}/restore [post]
+func (h *Handler) RestoreTask(c *gin.Context) {
+	// checking if the task is in the trash and the user can edit its list
+	id, ok := h.authorizeDeletedTask(c)
+	if !ok {
+		return
+	}
+
+	// restoring the task
+	task, err := h.Trash.RestoreTask(id, loggedInUser(c).Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.JSON(http.StatusAccepted, task)
+}
+
+// @Summary      Purge task
+// @Description  Deletes the task from the trash permanently
+// @Tags         Trash endpoints
+// @Param 		 id path int true "task ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the task does not exist or is not in the trash."
+// @Failure      409  {object}  util.Error "If the list of the task is deleted."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /trash/tasks/{id} [delete]
+func (h *Handler) PurgeTask(c *gin.Context) {
+	// checking if the task is in the trash and the user can edit its list
+	id, ok := h.authorizeDeletedTask(c)
+	if !ok {
+		return
+	}
+
+	// deleting the task permanently
+	err := h.Trash.PurgeTask(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary      Restore list
+// @Description  Restores the list from the trash together with the tasks deleted with it
+// @Tags         Trash endpoints
+// @Produce      json
+// @Param 		 id path int true "list ID"
+// @Success      202  {object}  model.List
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the list does not exist or is not in the trash."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /trash/lists/{id}/restore [post]
+func (h *Handler) RestoreList(c *gin.Context) {
+	// checking if the list is in the trash and the user owns it
+	id, ok := h.authorizeDeletedList(c)
+	if !ok {
+		return
+	}
+
+	// restoring the list with its tasks
+	list, err := h.Trash.RestoreList(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.JSON(http.StatusAccepted, list)
+}
+
+// @Summary      Purge list
+// @Description  Deletes the list from the trash permanently together with all of its tasks
+// @Tags         Trash endpoints
+// @Param 		 id path int true "list ID"
+// @Success      202
+// @Failure      400  {object}  util.Error "If the id is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the user has no permission to do this."
+// @Failure      404  {object}  util.Error "If the list does not exist or is not in the trash."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /trash/lists/{id} [delete]
+func (h *Handler) PurgeList(c *gin.Context) {
+	// checking if the list is in the trash and the user owns it
+	id, ok := h.authorizeDeletedList(c)
+	if !ok {
+		return
+	}
+
+	// deleting the list permanently
+	err := h.Trash.PurgeList(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// success
+	c.Status(http.StatusAccepted)
+}
+
+// parses the id of the task and checks whether the logged-in user can edit its list
+// and the task is in the trash on its own, otherwise it writes the error response and returns false
+// the permission is checked first, so the state of the trash is only revealed to the users who can see it
+func (h *Handler) authorizeDeletedTask(c *gin.Context) (int, bool) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return 0, false
+	}
+
+	// getting the task and its list, even if they are in the trash
+	task, err := h.Trash.GetTaskWithTrashed(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID does not exist."})
+		return 0, false
+	}
+
+	list, err := h.Trash.GetListWithTrashed(task.ListId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID does not exist."})
+		return 0, false
+	}
+
+	// a list in the trash belongs only to its owner, otherwise the user has to be able to edit it
+	if list.DeletedAt.Valid {
+		if !requireOwner(c, list.OwnerId) {
+			return 0, false
+		}
+	} else if !h.requireRole(c, list.Id, model.RoleEditor) {
+		return 0, false
+	}
+
+	// checking if the task is in the trash
+	if !task.DeletedAt.Valid {
+		c.JSON(http.StatusNotFound, util.Error{Message: "Task with this ID is not in the trash."})
+		return 0, false
+	}
+
+	// the tasks deleted with their list can only be restored or purged with the list
+	if task.DeletedWithList || list.DeletedAt.Valid {
+		c.JSON(http.StatusConflict, util.Error{Message: "The list of the task is deleted, restore the list instead."})
+		return 0, false
+	}
+
+	return id, true
+}
+
+// parses the id of the list and checks whether the logged-in user owns it and it's in the trash,
+// otherwise it writes the error response and returns false
+func (h *Handler) authorizeDeletedList(c *gin.Context) (int, bool) {
+	// parsing the id parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please specify a valid id."})
+		return 0, false
+	}
+
+	// getting the list, even if it's in the trash
+	list, err := h.Trash.GetListWithTrashed(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.Error{Message: "List with this ID does not exist."})
+		return 0, false
+	}
+
+	if !requireOwner(c, list.OwnerId) {
+		return 0, false
+	}
+
+	// checking if the list is in the trash
+	if !list.DeletedAt.Valid {
+		c.JSON(http.StatusNotFound, util.Error{Message: "List with this ID is not in the trash."})
+		return 0, false
+	}
+
+	return id, true
+}
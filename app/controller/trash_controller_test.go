@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/model"
+)
+
+func TestTrashOverHttp(t *testing.T) {
+	r, store := newIntegrationRouter(t)
+	owner, ownerCookie := loginTestUser(t, store, "Trash Owner")
+	viewer, viewerCookie := loginTestUser(t, store, "Trash Viewer")
+
+	list, err := store.CreateList(model.List{OwnerId: owner.Id, Name: "Shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateMember(model.Member{ListId: list.Id, UserId: viewer.Id, Role: model.RoleViewer}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := store.CreateTask(model.Task{ListId: list.Id, CreatedById: &owner.Id, Title: "Trashed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskId := strconv.Itoa(task.Id)
+	listId := strconv.Itoa(list.Id)
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		cookie string
+		status int
+	}{
+		{"restoring a task that is not deleted", "POST", "/api/v1/trash/tasks/" + taskId + "/restore", ownerCookie, http.StatusNotFound},
+		{"deleting the task", "DELETE", "/api/v1/tasks/" + taskId, ownerCookie, http.StatusAccepted},
+		{"restoring as a viewer", "POST", "/api/v1/trash/tasks/" + taskId + "/restore", viewerCookie, http.StatusForbidden},
+		{"purging as a viewer", "DELETE", "/api/v1/trash/tasks/" + taskId, viewerCookie, http.StatusForbidden},
+		{"restoring the task", "POST", "/api/v1/trash/tasks/" + taskId + "/restore", ownerCookie, http.StatusAccepted},
+		{"deleting the list", "DELETE", "/api/v1/lists/" + listId, ownerCookie, http.StatusAccepted},
+		{"restoring a task of the deleted list", "POST", "/api/v1/trash/tasks/" + taskId + "/restore", ownerCookie, http.StatusConflict},
+		{"restoring the list of another user", "POST", "/api/v1/trash/lists/" + listId + "/restore", viewerCookie, http.StatusForbidden},
+		{"getting the tasks of the deleted list", "GET", "/api/v1/tasks/list/" + listId, ownerCookie, http.StatusNotFound},
+		{"purging the list", "DELETE", "/api/v1/trash/lists/" + listId, ownerCookie, http.StatusAccepted},
+		{"restoring the purged list", "POST", "/api/v1/trash/lists/" + listId + "/restore", ownerCookie, http.StatusNotFound},
+		{"restoring the purged task", "POST", "/api/v1/trash/tasks/" + taskId + "/restore", ownerCookie, http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		if w := serve(r, step.method, step.path, "", step.cookie); w.Code != step.status {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.status, w.Body)
+		}
+	}
+
+	w := serve(r, "GET", "/api/v1/trash", "", ownerCookie)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("trash after purging = %d %s, want it empty", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/0l1v3rr/todo/app/mail"
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// @Summary      Edit profile
+// @Description  Changes the name, the email and the avatar of the logged-in user.
+// @Description  A new email address is only saved after it's verified with the link sent to it, until then it's returned as pendingEmail.
+// @Description  The avatar has to be a file uploaded with the file endpoint, or empty to remove it.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        profile body model.EditProfile true "The new profile"
+// @Success      200  {object}  model.User "If the profile has been saved."
+// @Failure      400  {object}  util.Error "If the profile is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      409  {object}  util.Error "If the new email is already registered."
+// @Failure      500  {object}  util.Error "If there was a db error."
+// @Router       /user [put]
+func (h *Handler) EditProfile(c *gin.Context) {
+	// binding the profile from the body
+	var profile model.EditProfile
+
+	if err := c.ShouldBindBodyWith(&profile, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid profile."})
+		return
+	}
+
+	// getting the logged-in user
+	user := loggedInUser(c)
+
+	// only the changed fields are validated, so the users created with an oidc provider
+	// can keep a name the registration wouldn't allow
+	if profile.Name != user.Name {
+		if ok, msg := model.ValidateName(profile.Name); !ok {
+			c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+			return
+		}
+	}
+
+	// the avatar has to be an uploaded image
+	if profile.AvatarUrl != "" && !isUploadedFile(profile.AvatarUrl) {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide an uploaded image as the avatar."})
+		return
+	}
+
+	user.Name = profile.Name
+	user.AvatarUrl = profile.AvatarUrl
+
+	// if the email has changed, it has to be verified before it's used
+	if profile.Email == user.Email {
+		user.PendingEmail = ""
+	} else if profile.Email != user.PendingEmail {
+		if ok, msg := model.ValidateEmail(profile.Email); !ok {
+			c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+			return
+		}
+
+		if h.Users.ExistsByEmail(profile.Email) {
+			c.JSON(http.StatusConflict, util.Error{Message: "This email is already registered."})
+			return
+		}
+
+		// the verification link is sent before the profile is saved,
+		// so the new email isn't left pending without a link if the sending fails
+		token, err := h.Tokens.CreateToken(user.Id, model.TokenEmail, verificationTTL)
+		if err == nil {
+			err = mail.SendVerification(profile.Email, user.Name, token)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to send the verification email."})
+			return
+		}
+
+		user.PendingEmail = profile.Email
+	}
+
+	// saving the profile
+	if err := h.Users.EditUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	user.Password = ""
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Change password
+// @Description  Changes the password of the logged-in user.
+// @Description  The current password is not required if the user has no password yet, because it was created with an OIDC provider.
+// @Description  Every other session of the user is revoked.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        password body model.ChangePassword true "The current and the new password"
+// @Success      200  {object}  util.Success "If the password has been changed."
+// @Failure      400  {object}  util.Error "If the new password is not valid."
+// @Failure      401  {object}  util.Error "If the user is not logged in."
+// @Failure      403  {object}  util.Error "If the current password is incorrect."
+// @Failure      500  {object}  util.Error "If there was a server error while changing the password."
+// @Router       /user/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	// binding the passwords from the body
+	var body model.ChangePassword
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid password."})
+		return
+	}
+
+	// getting the logged-in user, this can't be done with an access token
+	user, session, ok := requireSession(c)
+	if !ok {
+		return
+	}
+
+	// checking the current password
+	// the users created with an oidc provider can set a password without one
+	if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.CurrentPassword)) != nil {
+		c.JSON(http.StatusForbidden, util.Error{Message: "Incorrect password."})
+		return
+	}
+
+	// validating the new password
+	ok, msg := model.ValidatePassword(body.NewPassword)
+	if !ok {
+		c.JSON(http.StatusBadRequest, util.Error{Message: msg})
+		return
+	}
+
+	// changing the password, which logs out every other session
+	if err := h.Users.SetPassword(user.Id, body.NewPassword, session.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: "Failed to change the password."})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "Your password has been changed."})
+}
+
+// checks whether the url points to a file uploaded with UploadFile
+func isUploadedFile(url string) bool {
+	name := strings.TrimPrefix(url, "/assets/images/")
+	if name == url || name == "" || path.Base(name) != name {
+		return false
+	}
+
+	info, err := os.Stat("images/" + name)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/0l1v3rr/todo/app/mail"
+)
+
+// a mailer that keeps the emails, or fails if err is set
+type testMailer struct {
+	sent []string
+	err  error
+}
+
+func (m *testMailer) Send(to string, subject string, body string) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+// replaces the mailer of the app for the test
+func useTestMailer(t *testing.T) *testMailer {
+	mailer := &testMailer{}
+	previous := mail.Client
+	mail.Client = mailer
+	t.Cleanup(func() { mail.Client = previous })
+
+	return mailer
+}
+
+func TestEditProfile(t *testing.T) {
+	r, store := newIntegrationRouter(t)
+	mailer := useTestMailer(t)
+
+	// the users of the oidc providers can have names the registration doesn't allow
+	user, cookie := loginTestUser(t, store, "Bo")
+	otherUser, _ := loginTestUser(t, store, "Other User")
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"keeping a short name", `{"name":"Bo","email":"bo@example.com"}`, http.StatusOK},
+		{"changing to a short name", `{"name":"Al","email":"bo@example.com"}`, http.StatusBadRequest},
+		{"changing to an invalid email", `{"name":"Bo","email":"bo at example.com"}`, http.StatusBadRequest},
+		{"changing to a registered email", `{"name":"Bo","email":"` + otherUser.Email + `"}`, http.StatusConflict},
+	}
+
+	for _, test := range tests {
+		if w := serve(r, "PUT", "/api/v1/user", test.body, cookie); w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d: %s", test.name, w.Code, test.status, w.Body)
+		}
+	}
+	if len(mailer.sent) != 0 {
+		t.Errorf("emails were sent to %v", mailer.sent)
+	}
+
+	// the new email isn't saved if the verification link can't be sent
+	mailer.err = errors.New("smtp is down")
+	if w := serve(r, "PUT", "/api/v1/user", `{"name":"Bo","email":"new@example.com"}`, cookie); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if saved, _ := store.GetUserById(user.Id); saved.PendingEmail != "" {
+		t.Errorf("pending email = %s after the failed email", saved.PendingEmail)
+	}
+
+	mailer.err = nil
+	if w := serve(r, "PUT", "/api/v1/user", `{"name":"Bo","email":"new@example.com"}`, cookie); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	saved, _ := store.GetUserById(user.Id)
+	if saved.PendingEmail != "new@example.com" || saved.Email != user.Email || len(mailer.sent) != 1 || mailer.sent[0] != "new@example.com" {
+		t.Errorf("user = %+v with emails sent to %v, want the new email pending and a link sent to it", saved, mailer.sent)
+	}
+
+	// the pending email is kept without a new link, and cancelled with the old email
+	serve(r, "PUT", "/api/v1/user", `{"name":"Bo","email":"new@example.com"}`, cookie)
+	serve(r, "PUT", "/api/v1/user", `{"name":"Bo","email":"`+user.Email+`"}`, cookie)
+	if saved, _ := store.GetUserById(user.Id); saved.PendingEmail != "" || len(mailer.sent) != 1 {
+		t.Errorf("user = %+v with emails sent to %v", saved, mailer.sent)
+	}
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/mail"
+	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// how long a verification link is valid
+	verificationTTL = 24 * time.Hour
+
+	// how long a password reset link is valid
+	resetTTL = time.Hour
+
+	// how often a verification or password reset email can be requested for the same user
+	resendCooldown = time.Minute
+)
+
+// @Summary      Verify email
+// @Description  Verifies the email address of a user with the token sent in the verification email, and enables the user.
+// @Description  If the token was sent to a new email address of the user, the email of the user is changed.
+// @Tags         User endpoints
+// @Produce      json
+// @Param        token query string true "Verification token"
+// @Success      200  {object}  util.Success "If the email address has been verified."
+// @Failure      400  {object}  util.Error "If the token is invalid, expired or already used."
+// @Failure      409  {object}  util.Error "If the new email address has been registered in the meantime."
+// @Failure      500  {object}  util.Error "If there was a server error while verifying the user."
+// @Router       /verify [get]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	// getting the token from the query
+	raw := c.Query("token")
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a verification token."})
+		return
+	}
+
+	// using the token
+	// the token is either from the registration or from an email change
+	token, err := h.Tokens.UseToken(raw, model.TokenVerify)
+	if err == model.ErrInvalidToken {
+		token, err = h.Tokens.UseToken(raw, model.TokenEmail)
+	}
+	if err == model.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "This verification link is invalid or has expired."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	// changing the email of the user
+	if token.Purpose == model.TokenEmail {
+		err = h.Users.ConfirmEmail(token.UserId)
+		if err == model.ErrInvalidToken {
+			c.JSON(http.StatusBadRequest, util.Error{Message: "This verification link is invalid or has expired."})
+			return
+		}
+		if err == model.ErrEmailTaken {
+			c.JSON(http.StatusConflict, util.Error{Message: "This email is already registered."})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, util.Success{Message: "Your email address has been changed!"})
+		return
+	}
+
+	// enabling the user
+	if err := h.Users.EnableUser(token.UserId); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Error{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.Success{Message: "Your email address has been verified!"})
+}
+
+// @Summary      Resend verification email
+// @Description  Sends a new verification email to the user with the given email address.
+// @Description  The response is the same whether or not the email address is registered,
+// @Description  and no email is sent if one has been requested in the last minute.
+// @Tags         User endpoints
+// @Accept       json
+// @Produce      json
+// @Param        email body model.EmailRequest true "The email address to verify"
+// @Success      200  {object}  util.Success "If the request has been accepted."
+// @Failure      400  {object}  util.Error "If the provided email is not valid."
+// @Router       /verify/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	// binding the email from the body
+	var body model.EmailRequest
+
+	if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, util.Error{Message: "Please provide a valid email address."})
+		return
+	}
+
+	accepted := util.Success{Message: "If this email address is registered and not verified yet, we have sent a new verification email."}
+
+	// unknown and already verified users get the same response
+	user, err := h.Users.GetUserByEmail(body.Email)
+	if err != nil || user.Id == 0 || user.IsEnabled {
+		c.JSON(http.StatusOK, accepted)
+		return
+	}
+
+	// the emails of the user are rate limited silently, and a failed email is only logged,
+	// so the response doesn't reveal which email addresses are registered and not verified yet
+	last, sent := h.Tokens.LastTokenCreatedAt(user.Id, model.TokenVerify)
+	if !sent || time.Since(last) >= resendCooldown {
+		h.sendVerification(user)
+	}
+
+	c.JSON(http.StatusOK, accepted)
+}
+
+// creates a verification token for the user and emails the link
+func (h *Handler) sendVerification(user model.User) error {
+	token, err := h.Tokens.CreateToken(user.Id, model.TokenVerify, verificationTTL)
+	if err != nil {
+		log.Println("Failed to create the verification token: " + err.Error())
+		return err
+	}
+
+	if err := mail.SendVerification(user.Email, user.Name, token); err != nil {
+		log.Println("Failed to send the verification email: " + err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -115,7 +115,7 @@
         },
         "/lists/user/{userId}": {
             "get": {
-                "description": "Returns all the lists the specified user has",
+                "description": "Returns all the lists the specified user owns or is a member of, a page at a time if a limit is specified.\nThe urls of the next and the previous pages are in the Link header.",
                 "produces": [
                     "application/json"
                 ],
@@ -130,6 +130,38 @@
                         "name": "userId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "position",
+                            "name",
+                            "created"
+                        ],
+                        "type": "string",
+                        "default": "position",
+                        "description": "the order of the lists",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the maximum number of lists",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page from the Link header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only the lists with a name containing this text",
+                        "name": "name",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -140,10 +172,16 @@
                             "items": {
                                 "$ref": "#/definitions/model.List"
                             }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "the urls of the next and the previous pages"
+                            }
                         }
                     },
                     "400": {
-                        "description": "If the id is not valid.",
+                        "description": "If the id, the sort or the page is not valid.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -169,32 +207,50 @@
                 }
             }
         },
-        "/lists/{url}": {
-            "get": {
-                "description": "Returns all the lists the specified user has",
+        "/lists/{id}": {
+            "put": {
+                "description": "Edits the list",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "List endpoints"
                 ],
-                "summary": "Get lists",
+                "summary": "Edit list",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "list URL",
-                        "name": "url",
+                        "description": "List to edit",
+                        "name": "list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.List"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "list ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "$ref": "#/definitions/model.List"
                         }
                     },
+                    "400": {
+                        "description": "If the list or the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
                     "401": {
                         "description": "If the user is not logged in.",
                         "schema": {
@@ -202,7 +258,7 @@
                         }
                     },
                     "403": {
-                        "description": "If the user doesn't have permission to view the list.",
+                        "description": "If the user has no permission to do this.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -212,125 +268,125 @@
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
                     }
                 }
-            }
-        },
-        "/login": {
-            "post": {
-                "description": "Logs in a user and saves the JWT in cookies.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Moves the list together with all of its tasks to the trash",
                 "tags": [
-                    "User endpoints"
+                    "List endpoints"
                 ],
-                "summary": "Login",
+                "summary": "Delete list",
                 "parameters": [
                     {
-                        "description": "User to log in",
-                        "name": "user",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/model.LoginUser"
-                        }
+                        "type": "integer",
+                        "description": "list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "If the login was successful.",
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
                         "schema": {
-                            "$ref": "#/definitions/util.Success"
+                            "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "400": {
-                        "description": "If the provided user is not valid.",
+                    "401": {
+                        "description": "If the user is not logged in.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "403": {
-                        "description": "If the password is incorrect or the user is not activated.",
+                        "description": "If the user has no permission to do this.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "404": {
-                        "description": "If the user with the specified email does not exist.",
+                        "description": "If the list does not exist.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
-                    }
-                }
-            }
-        },
-        "/logout": {
-            "post": {
-                "description": "Logs out the currently logged-in user.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "User endpoints"
-                ],
-                "summary": "Logout",
-                "responses": {
-                    "200": {
-                        "description": "If the logout was success.",
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
                         "schema": {
-                            "$ref": "#/definitions/util.Success"
+                            "$ref": "#/definitions/util.Error"
                         }
                     }
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "Registers a new user into the database.",
+        "/lists/{id}/position": {
+            "put": {
+                "description": "Moves the list right before or after another list in the sidebar of the user",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "User endpoints"
+                    "List endpoints"
                 ],
-                "summary": "Registration",
+                "summary": "Reorder list",
                 "parameters": [
                     {
-                        "description": "User to register",
-                        "name": "user",
+                        "description": "The list to move before or after",
+                        "name": "reorder",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/model.Reorder"
                         }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "If the user has been created successfully.",
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "If the id or the target is not valid.",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "400": {
-                        "description": "If the provided user is not valid.",
+                    "401": {
+                        "description": "If the user is not logged in.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "409": {
-                        "description": "If the specified email already exists.",
+                    "403": {
+                        "description": "If the user doesn't have permission to view the list.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the list does not exist.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "500": {
-                        "description": "If there was a server error while creating the user.",
+                        "description": "If there was a db error.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -338,21 +394,21 @@
                 }
             }
         },
-        "/tasks/list/{id}": {
+        "/lists/{url}": {
             "get": {
-                "description": "Returns all the tasks in the specified list",
+                "description": "Returns all the lists the specified user has",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Task endpoints"
+                    "List endpoints"
                 ],
-                "summary": "Get list tasks",
+                "summary": "Get lists",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "list ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "list URL",
+                        "name": "url",
                         "in": "path",
                         "required": true
                     }
@@ -361,16 +417,7 @@
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Task"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "If the id is not valid.",
-                        "schema": {
-                            "$ref": "#/definitions/util.Error"
+                            "$ref": "#/definitions/model.List"
                         }
                     },
                     "401": {
@@ -386,13 +433,7 @@
                         }
                     },
                     "404": {
-                        "description": "If the list with this id does not exist.",
-                        "schema": {
-                            "$ref": "#/definitions/util.Error"
-                        }
-                    },
-                    "500": {
-                        "description": "If there was a db error..",
+                        "description": "If the list does not exist.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -400,9 +441,9 @@
                 }
             }
         },
-        "/tasks/{id}": {
-            "put": {
-                "description": "Edits the task",
+        "/login": {
+            "post": {
+                "description": "Logs in a user and saves a short-lived JWT and a refresh token in cookies.\nThe JWT can be renewed with the refresh endpoint until the session is revoked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -410,68 +451,62 @@
                     "application/json"
                 ],
                 "tags": [
-                    "Task endpoints"
+                    "User endpoints"
                 ],
-                "summary": "Edit task",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "description": "Task to create",
-                        "name": "task",
+                        "description": "User to log in",
+                        "name": "user",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Task"
+                            "$ref": "#/definitions/model.LoginUser"
                         }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "If the login was successful.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
                     "202": {
-                        "description": "Accepted",
+                        "description": "If the user has 2FA, and the login has to be finished with a code.",
                         "schema": {
-                            "$ref": "#/definitions/model.Task"
+                            "$ref": "#/definitions/model.MfaChallenge"
                         }
                     },
                     "400": {
-                        "description": "If the task or the id is not valid.",
+                        "description": "If the provided user is not valid.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "401": {
-                        "description": "If the user is not logged in.",
+                        "description": "If the email or the password is incorrect.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "403": {
-                        "description": "If the user has no permission to do this.",
-                        "schema": {
-                            "$ref": "#/definitions/util.Error"
-                        }
-                    },
-                    "404": {
-                        "description": "If the task does not exist.",
+                        "description": "If the user is not activated.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "500": {
-                        "description": "If there was a db error.",
+                    "429": {
+                        "description": "If there were too many attempts. The Retry-After header contains the seconds to wait.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/login/2fa": {
             "post": {
-                "description": "Creates a new task in the db",
+                "description": "Finishes the login of a user with 2FA, with a code of the authenticator app or a recovery code.\nThe mfaToken is returned by the login endpoint, and it's invalidated after 5 wrong codes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -479,47 +514,131 @@
                     "application/json"
                 ],
                 "tags": [
-                    "Task endpoints"
+                    "User endpoints"
                 ],
-                "summary": "Create task",
+                "summary": "Login with 2FA",
                 "parameters": [
                     {
-                        "description": "Task to create",
-                        "name": "task",
+                        "description": "The token of the login and the code",
+                        "name": "login",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Task"
+                            "$ref": "#/definitions/model.LoginTotp"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "If the login was successful.",
                         "schema": {
-                            "$ref": "#/definitions/model.Task"
+                            "$ref": "#/definitions/util.Success"
                         }
                     },
                     "400": {
-                        "description": "If the task is not valid.",
+                        "description": "If the token is invalid or has expired.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "401": {
-                        "description": "If the user is not logged in.",
+                    "403": {
+                        "description": "If the code is invalid.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "403": {
-                        "description": "If the user doesn't have permission to create task in the list.",
+                    "429": {
+                        "description": "If there were too many attempts. The Retry-After header contains the seconds to wait.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
-                    "404": {
-                        "description": "If the list with the specified ID does not exist.",
+                    "500": {
+                        "description": "If there was a server error while logging in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Logs out the currently logged-in user and revokes the session.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "If the logout was success.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    }
+                }
+            }
+        },
+        "/members": {
+            "post": {
+                "description": "Shares the list with the user with the specified email\nThe role can be viewer or editor, only the creator of the list is its owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Member endpoints"
+                ],
+                "summary": "Invite member",
+                "parameters": [
+                    {
+                        "description": "User to invite",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InviteMember"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "If the invitation is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user doesn't have permission to share the list.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the list or the invited user does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the invited user already has access to the list.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -531,27 +650,35 @@
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes the task",
+            }
+        },
+        "/members/list/{listId}": {
+            "get": {
+                "description": "Returns all the users the specified list is shared with",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Task endpoints"
+                    "Member endpoints"
                 ],
-                "summary": "Delete task",
+                "summary": "Get list members",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "task ID",
-                        "name": "id",
+                        "description": "list ID",
+                        "name": "listId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Task"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Member"
+                            }
                         }
                     },
                     "400": {
@@ -567,13 +694,13 @@
                         }
                     },
                     "403": {
-                        "description": "If the user has no permission to do this.",
+                        "description": "If the user doesn't have permission to view the list.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "404": {
-                        "description": "If the task does not exist.",
+                        "description": "If the list with this id does not exist.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -585,20 +712,34 @@
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Changes the task status to its opposite value",
+            }
+        },
+        "/members/{id}": {
+            "put": {
+                "description": "Changes the role of the member\nThe role can be viewer or editor, only the creator of the list is its owner",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Task endpoints"
+                    "Member endpoints"
                 ],
-                "summary": "Change task status",
+                "summary": "Change member role",
                 "parameters": [
+                    {
+                        "description": "Member with the new role",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Member"
+                        }
+                    },
                     {
                         "type": "integer",
-                        "description": "task ID",
+                        "description": "member ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -608,8 +749,59 @@
                     "202": {
                         "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/model.Task"
+                            "$ref": "#/definitions/model.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "If the role or the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the member does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
                         }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the member from the list, members can also remove themselves",
+                "tags": [
+                    "Member endpoints"
+                ],
+                "summary": "Remove member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
                     },
                     "400": {
                         "description": "If the id is not valid.",
@@ -630,7 +822,13 @@
                         }
                     },
                     "404": {
-                        "description": "If the task doesn't exist.",
+                        "description": "If the member does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -638,46 +836,83 @@
                 }
             }
         },
-        "/tasks/{url}": {
+        "/oidc/providers": {
             "get": {
-                "description": "Returns the task with the specified url",
+                "description": "Returns the names of the configured OpenID Connect providers.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Task endpoints"
+                    "User endpoints"
                 ],
-                "summary": "Get tasks by URL",
+                "summary": "Get OIDC providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/{provider}/callback": {
+            "get": {
+                "description": "Logs in the user after the OpenID Connect provider redirected back.\nOn the first login the user with the same verified email is linked, or a new user is created without a password.\nUsers with 2FA are redirected to the frontend with an mfaToken in the fragment of the url instead of a session,\nand the login has to be finished with a code at /login/2fa, the same as after the password.",
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "OIDC callback",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "task URL",
-                        "name": "url",
+                        "description": "Name of the provider",
+                        "name": "provider",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of the login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.Task"
-                        }
+                    "302": {
+                        "description": ""
                     },
-                    "401": {
-                        "description": "If the user is not logged in.",
+                    "400": {
+                        "description": "If the login is invalid or has expired.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "403": {
-                        "description": "If the user doesn't have permission to view the list the task is in.",
+                        "description": "If the email of the account is not verified by the provider.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
                     },
                     "404": {
-                        "description": "If the task doesn't exist.",
+                        "description": "If there is no provider with this name.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "502": {
+                        "description": "If the provider rejected the login.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -685,25 +920,2575 @@
                 }
             }
         },
-        "/user": {
+        "/oidc/{provider}/login": {
             "get": {
-                "description": "Returns the currently logged-in user.",
+                "description": "Redirects to the login page of the OpenID Connect provider.\nThe provider redirects back to the callback endpoint, which logs in the user.",
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "OIDC login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": "If there is no provider with this name.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "502": {
+                        "description": "If the provider can't be reached.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Sends a password reset link to the user with the given email address.\nThe response is the same whether or not the email address is registered.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "User endpoints"
                 ],
-                "summary": "Logged In User",
+                "summary": "Forgot password",
+                "parameters": [
+                    {
+                        "description": "The email address of the account",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "If the user is logged in.",
+                        "description": "If the request has been accepted.",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/util.Success"
                         }
                     },
-                    "401": {
-                        "description": "If the user is not logged in.",
+                    "400": {
+                        "description": "If the provided email is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Sets a new password with the token sent in the password reset email.\nEvery existing login and personal access token of the user is invalidated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "The token and the new password",
+                        "name": "reset",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResetPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the password has been changed.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the password is not valid, or the token is invalid, expired or already used.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a server error while changing the password.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "Issues a new JWT with the refresh token saved in the cookies.\nThe refresh token is replaced by a new one, and reusing an old refresh token revokes the session.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Refresh",
+                "responses": {
+                    "200": {
+                        "description": "If the tokens have been refreshed.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "401": {
+                        "description": "If the refresh token is missing, expired or revoked.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a server error while refreshing the tokens.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Registers a new user into the database.\nThe user is disabled until the email address is verified with the link sent in an email.\nIf the email is already registered, the response is the same, and the owner of the email gets a notice instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Registration",
+                "parameters": [
+                    {
+                        "description": "User to register",
+                        "name": "user",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "If the registration has been accepted.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the provided user is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a server error while creating the user.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Searches the tasks and the lists the user has access to.\nThe query can contain the is:done, is:open, list:\u003curl\u003e, created:\u003cfrom\u003e..\u003cto\u003e and due:\u003cfrom\u003e..\u003cto\u003e filters,\nwhere the dates are in YYYY-MM-DD format and either end of the range can be left empty.\nEvery match is ranked by relevance, and the best 50 tasks and lists are returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search endpoints"
+                ],
+                "summary": "Search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "the IANA time zone of the dates in the query",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the query or the time zone is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "description": "Returns the active sessions of the logged-in user, the most recently used first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session endpoints"
+                ],
+                "summary": "Get sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Session"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revokes every session of the logged-in user, including the current one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session endpoints"
+                ],
+                "summary": "Log out everywhere",
+                "responses": {
+                    "200": {
+                        "description": "If the sessions have been revoked.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "delete": {
+                "description": "Revokes a session of the logged-in user, which logs out the device of the session.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session endpoints"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the session has been revoked.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the user has no active session with this id.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Returns all the tags of the logged-in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag endpoints"
+                ],
+                "summary": "Get tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Tag"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag endpoints"
+                ],
+                "summary": "Create tag",
+                "parameters": [
+                    {
+                        "description": "Tag to create",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "If the tag is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the user already has a tag with this name.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "description": "Renames or recolors the tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag endpoints"
+                ],
+                "summary": "Edit tag",
+                "parameters": [
+                    {
+                        "description": "Tag to edit",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "If the tag or the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the tag does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the user already has a tag with this name.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the tag and removes it from every task",
+                "tags": [
+                    "Tag endpoints"
+                ],
+                "summary": "Delete tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the tag does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}/merge": {
+            "post": {
+                "description": "Moves every task of the tag to another tag and deletes the tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag endpoints"
+                ],
+                "summary": "Merge tags",
+                "parameters": [
+                    {
+                        "description": "The tag to merge into",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeTags"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the tag to merge",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "If the ids are not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If one of the tags does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "Returns the tasks that have every specified tag of the user from every list the user has access to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Get tasks by tags",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "tag name",
+                        "name": "tag",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If no tag was specified.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/copy": {
+            "post": {
+                "description": "Copies the tasks with their checklists and the tags of the user to the top of another list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Copy tasks",
+                "parameters": [
+                    {
+                        "description": "The tasks and the destination list",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TransferTasks"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the body is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to view the source or edit the destination list.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If a task or the destination list does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/due/{period}": {
+            "get": {
+                "description": "Returns the open tasks that are overdue, due today or due this week from every list the user has access to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Get due tasks",
+                "parameters": [
+                    {
+                        "enum": [
+                            "overdue",
+                            "today",
+                            "week"
+                        ],
+                        "type": "string",
+                        "description": "the period the tasks are due in",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "the IANA time zone of the user",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the period or the time zone is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/list/{id}": {
+            "get": {
+                "description": "Returns the tasks in the specified list, a page at a time if a limit is specified.\nThe urls of the next and the previous pages are in the Link header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Get list tasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "position",
+                            "created",
+                            "due",
+                            "priority"
+                        ],
+                        "type": "string",
+                        "default": "position",
+                        "description": "the order of the tasks",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the maximum number of tasks",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page from the Link header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only the done or the open tasks",
+                        "name": "isDone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only the tasks created at or after this RFC 3339 time",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only the tasks created before this RFC 3339 time",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only the tasks with a title containing this text",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "the urls of the next and the previous pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the id, the sort, the filters or the page are not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user doesn't have permission to view the list.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the list with this id does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error..",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/move": {
+            "post": {
+                "description": "Moves the tasks to the top of another list, the tags of the users who can't access that list are removed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Move tasks",
+                "parameters": [
+                    {
+                        "description": "The tasks and the destination list",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TransferTasks"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the body is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to edit the source or the destination list.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If a task or the destination list does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/top": {
+            "get": {
+                "description": "Returns the highest priority open tasks from every list the user has access to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Get top priority tasks",
+                "parameters": [
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "the number of tasks",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the limit is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "put": {
+                "description": "Edits the task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Edit task",
+                "parameters": [
+                    {
+                        "description": "Task to create",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "If the task or the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new task in the db",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Create task",
+                "parameters": [
+                    {
+                        "description": "Task to create",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "If the task is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user doesn't have permission to create task in the list.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the list with the specified ID does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Moves the task to the trash",
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Delete task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes the task status to its opposite value",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Change task status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "whether to complete the checklist items when the task is done",
+                        "name": "completeItems",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "the IANA time zone of the weekdays and the time of the day of the recurrence",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id or the time zone is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/items": {
+            "post": {
+                "description": "Adds a new checklist item to the end of the checklist of the task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Item endpoints"
+                ],
+                "summary": "Create checklist item",
+                "parameters": [
+                    {
+                        "description": "Item to create",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Item"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Item"
+                        }
+                    },
+                    "400": {
+                        "description": "If the item or the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/items/{itemId}": {
+            "put": {
+                "description": "Edits the title, the status or the position of the checklist item",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Item endpoints"
+                ],
+                "summary": "Edit checklist item",
+                "parameters": [
+                    {
+                        "description": "Item to edit",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Item"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Item"
+                        }
+                    },
+                    "400": {
+                        "description": "If the item or the ids are not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task or the item doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the checklist item",
+                "tags": [
+                    "Item endpoints"
+                ],
+                "summary": "Delete checklist item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "If the ids are not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task or the item doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/position": {
+            "put": {
+                "description": "Moves the task right before or after another task of the same list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Reorder task",
+                "parameters": [
+                    {
+                        "description": "The task to move before or after",
+                        "name": "reorder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Reorder"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id or the target is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task does not exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{url}": {
+            "get": {
+                "description": "Returns the task with the specified url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Get tasks by URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task URL",
+                        "name": "url",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user doesn't have permission to view the list the task is in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{url}/items": {
+            "get": {
+                "description": "Returns the checklist items of the task with the specified url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Item endpoints"
+                ],
+                "summary": "Get checklist items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task URL",
+                        "name": "url",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Item"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user doesn't have permission to view the list the task is in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{url}/occurrences": {
+            "get": {
+                "description": "Returns the next occurrences of the recurring task with the specified url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task endpoints"
+                ],
+                "summary": "Get task occurrences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task URL",
+                        "name": "url",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "the number of occurrences",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "the IANA time zone of the weekdays and the time of the day of the rule",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "If the count or the time zone is not valid or the task is not recurring.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user doesn't have permission to view the list the task is in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens": {
+            "get": {
+                "description": "Returns the personal access tokens of the logged-in user, the newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Token endpoints"
+                ],
+                "summary": "Get access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AccessToken"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a personal access token, which can be sent in the Authorization header as a Bearer token.\nEvery token can read, the tasks:write and lists:write scopes allow changing the tasks and tags, or the lists and members.\nThe token is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Token endpoints"
+                ],
+                "summary": "Create access token",
+                "parameters": [
+                    {
+                        "description": "Token to create",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.NewAccessToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.AccessToken"
+                        }
+                    },
+                    "400": {
+                        "description": "If the token is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/{id}": {
+            "delete": {
+                "description": "Deletes a personal access token of the logged-in user, so it can't be used anymore.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Token endpoints"
+                ],
+                "summary": "Delete access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the token has been deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the user has no token with this id.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "description": "Returns the lists the user deleted and the tasks deleted from the lists the user can edit, the most recently deleted first.\nThe tasks deleted together with their list are restored with the list, so they are not returned.\nEverything is deleted permanently at purgeAt.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash endpoints"
+                ],
+                "summary": "Get trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TrashItem"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/lists/{id}": {
+            "delete": {
+                "description": "Deletes the list from the trash permanently together with all of its tasks",
+                "tags": [
+                    "Trash endpoints"
+                ],
+                "summary": "Purge list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the list does not exist or is not in the trash.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/lists/{id}/restore": {
+            "post": {
+                "description": "Restores the list from the trash together with the tasks deleted with it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash endpoints"
+                ],
+                "summary": "Restore list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.List"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the list does not exist or is not in the trash.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/tasks/{id}": {
+            "delete": {
+                "description": "Deletes the task from the trash permanently",
+                "tags": [
+                    "Trash endpoints"
+                ],
+                "summary": "Purge task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task does not exist or is not in the trash.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the list of the task is deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/tasks/{id}/restore": {
+            "post": {
+                "description": "Restores the task from the trash",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash endpoints"
+                ],
+                "summary": "Restore task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "If the id is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the user has no permission to do this.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "If the task does not exist or is not in the trash.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the list of the task is deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "description": "Returns the currently logged-in user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Logged In User",
+                "responses": {
+                    "200": {
+                        "description": "If the user is logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changes the name, the email and the avatar of the logged-in user.\nA new email address is only saved after it's verified with the link sent to it, until then it's returned as pendingEmail.\nThe avatar has to be a file uploaded with the file endpoint, or empty to remove it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Edit profile",
+                "parameters": [
+                    {
+                        "description": "The new profile",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EditProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the profile has been saved.",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "If the profile is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the new email is already registered.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa": {
+            "post": {
+                "description": "Creates a new secret for an authenticator app and returns it with its otpauth uri.\nTwo-factor authentication is only enabled after the secret is confirmed with a code.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Enroll 2FA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TotpSetup"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If 2FA is already enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Disables two-factor authentication and deletes the recovery codes.\nThe password is required, or a code if the user has no password because it was created with an OIDC provider.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Disable 2FA",
+                "parameters": [
+                    {
+                        "description": "Password of the user",
+                        "name": "confirm",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TotpDisable"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If 2FA has been disabled.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If 2FA is not enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the password or the code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/confirm": {
+            "post": {
+                "description": "Enables two-factor authentication with a code of the authenticator app, and returns the recovery codes.\nEvery recovery code can be used once instead of a code, and they are only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Confirm 2FA",
+                "parameters": [
+                    {
+                        "description": "Code of the authenticator app",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TotpCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "If the code is not valid, or 2FA has not been enrolled.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If 2FA is already enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a db error.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "put": {
+                "description": "Changes the password of the logged-in user.\nThe current password is not required if the user has no password yet, because it was created with an OIDC provider.\nEvery other session of the user is revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "The current and the new password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ChangePassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the password has been changed.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the new password is not valid.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "If the user is not logged in.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "If the current password is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a server error while changing the password.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify": {
+            "get": {
+                "description": "Verifies the email address of a user with the token sent in the verification email, and enables the user.\nIf the token was sent to a new email address of the user, the email of the user is changed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the email address has been verified.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the token is invalid, expired or already used.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "If the new email address has been registered in the meantime.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "If there was a server error while verifying the user.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify/resend": {
+            "post": {
+                "description": "Sends a new verification email to the user with the given email address.\nThe response is the same whether or not the email address is registered,\nand no email is sent if one has been requested in the last minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User endpoints"
+                ],
+                "summary": "Resend verification email",
+                "parameters": [
+                    {
+                        "description": "The email address to verify",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the request has been accepted.",
+                        "schema": {
+                            "$ref": "#/definitions/util.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "If the provided email is not valid.",
                         "schema": {
                             "$ref": "#/definitions/util.Error"
                         }
@@ -713,6 +3498,131 @@
         }
     },
     "definitions": {
+        "model.AccessToken": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2022-07-20T16:38:19Z"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lastUsedAt": {
+                    "type": "string",
+                    "example": "2022-07-21T08:12:45Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Backup script"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "todo_Xq3f"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "tasks:write"
+                    ]
+                },
+                "token": {
+                    "description": "the raw token, only returned when the token is created",
+                    "type": "string",
+                    "example": "todo_Xq3fK0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"
+                }
+            }
+        },
+        "model.ChangePassword": {
+            "type": "object",
+            "properties": {
+                "currentPassword": {
+                    "type": "string",
+                    "example": "SuperSecret69"
+                },
+                "newPassword": {
+                    "type": "string",
+                    "example": "EvenMoreSecret420"
+                }
+            }
+        },
+        "model.EditProfile": {
+            "type": "object",
+            "properties": {
+                "avatarURL": {
+                    "type": "string",
+                    "example": "/assets/images/hfhu39Hfeu.png"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "model.EmailRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                }
+            }
+        },
+        "model.InviteMember": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                },
+                "listId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                }
+            }
+        },
+        "model.Item": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "isDone": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "taskId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Buy milk"
+                }
+            }
+        },
         "model.List": {
             "type": "object",
             "properties": {
@@ -732,12 +3642,29 @@
                     "type": "integer",
                     "example": 1
                 },
+                "position": {
+                    "type": "number",
+                    "example": 1024
+                },
                 "url": {
                     "type": "string",
                     "example": "list-1"
                 }
             }
         },
+        "model.LoginTotp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfaToken": {
+                    "type": "string",
+                    "example": "qGfTnC0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"
+                }
+            }
+        },
         "model.LoginUser": {
             "type": "object",
             "properties": {
@@ -751,6 +3678,225 @@
                 }
             }
         },
+        "model.Member": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "listId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.MergeTags": {
+            "type": "object",
+            "properties": {
+                "intoId": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.MfaChallenge": {
+            "type": "object",
+            "properties": {
+                "mfaRequired": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "mfaToken": {
+                    "type": "string",
+                    "example": "qGfTnC0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"
+                }
+            }
+        },
+        "model.NewAccessToken": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Backup script"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "tasks:write"
+                    ]
+                }
+            }
+        },
+        "model.Progress": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "model.RecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k3x9q-2mfpa",
+                        "7hd2w-qp4zn"
+                    ]
+                }
+            }
+        },
+        "model.Reorder": {
+            "type": "object",
+            "properties": {
+                "afterId": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "beforeId": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.ResetPassword": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "SuperSecret69"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "qGfTnC0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"
+                }
+            }
+        },
+        "model.SearchResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "isDone": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "listId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "score": {
+                    "type": "number",
+                    "example": 3
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "Buy \u003cmark\u003emilk\u003c/mark\u003e and bread"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Buy milk"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "task"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "task-1"
+                }
+            }
+        },
+        "model.Session": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2022-07-20T16:38:19Z"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "device": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64) Firefox/102.0"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2022-08-20T16:38:19Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "lastSeenAt": {
+                    "type": "string",
+                    "example": "2022-07-21T08:12:45Z"
+                }
+            }
+        },
+        "model.Tag": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string",
+                    "example": "#ef4444"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "urgent"
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
         "model.Task": {
             "type": "object",
             "properties": {
@@ -766,6 +3912,10 @@
                     "type": "string",
                     "example": "This is a great task!"
                 },
+                "dueDate": {
+                    "type": "string",
+                    "example": "2022-07-03T17:00:00Z"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -778,6 +3928,31 @@
                     "type": "integer",
                     "example": 1
                 },
+                "position": {
+                    "type": "number",
+                    "example": 1024
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "progress": {
+                    "$ref": "#/definitions/model.Progress"
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "FREQ=WEEKLY;BYDAY=MO,FR"
+                },
+                "startDate": {
+                    "type": "string",
+                    "example": "2022-07-01T08:00:00Z"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
+                    }
+                },
                 "title": {
                     "type": "string",
                     "example": "Task"
@@ -788,9 +3963,101 @@
                 }
             }
         },
+        "model.TotpCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "model.TotpDisable": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "SuperSecret69"
+                }
+            }
+        },
+        "model.TotpSetup": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/ToDo:johndoe@gmail.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP\u0026issuer=ToDo"
+                }
+            }
+        },
+        "model.TransferTasks": {
+            "type": "object",
+            "properties": {
+                "listId": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "regenerateUrl": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "taskIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "model.TrashItem": {
+            "type": "object",
+            "properties": {
+                "deletedAt": {
+                    "type": "string",
+                    "example": "2022-07-21T08:12:45Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "listId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "purgeAt": {
+                    "type": "string",
+                    "example": "2022-08-20T08:12:45Z"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Buy milk"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "task"
+                }
+            }
+        },
         "model.User": {
             "type": "object",
             "properties": {
+                "avatarURL": {
+                    "type": "string",
+                    "example": "/assets/images/hfhu39Hfeu.png"
+                },
                 "email": {
                     "type": "string",
                     "example": "johndoe@gmail.com"
@@ -810,6 +4077,16 @@
                 "password": {
                     "type": "string",
                     "example": "secret"
+                },
+                "pendingEmail": {
+                    "description": "the new email address of the user until it's verified",
+                    "type": "string",
+                    "example": "john@doe.com"
+                },
+                "totpEnabled": {
+                    "description": "the secret of the authenticator app, and the time step of the last used code",
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
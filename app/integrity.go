@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0l1v3rr/todo/app/migration"
+	"github.com/0l1v3rr/todo/app/model"
+)
+
+const integrityUsage = "Usage: todo-backend integrity check|repair"
+
+// the integrity subcommand, which finds and removes the rows referencing missing rows,
+// for example in the databases created before the foreign keys
+func integrity(args []string) error {
+	if len(args) != 1 {
+		return errors.New(integrityUsage)
+	}
+
+	// connecting to the db without migrating it, the foreign keys can't be added while there are orphans
+	err := model.Connect()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "check":
+		orphans, err := migration.CheckIntegrity(model.DB)
+		if err != nil {
+			return err
+		}
+
+		if len(orphans) == 0 {
+			fmt.Println("no rows reference missing rows")
+			return nil
+		}
+
+		for _, o := range orphans {
+			fmt.Printf("%d rows of %s reference missing %s by %s\n", o.Count, o.Table, o.Parent, o.Column)
+		}
+		return errors.New("run `todo-backend integrity repair` to remove them")
+	case "repair":
+		repaired, err := migration.RepairIntegrity(model.DB)
+		if err != nil {
+			return err
+		}
+
+		if len(repaired) == 0 {
+			fmt.Println("nothing to repair")
+			return nil
+		}
+
+		for _, o := range repaired {
+			if o.Reassigned {
+				fmt.Printf("reassigned the %s of %d rows of %s\n", o.Column, o.Count, o.Table)
+			} else {
+				fmt.Printf("deleted %d rows of %s referencing missing %s\n", o.Count, o.Table, o.Parent)
+			}
+		}
+	default:
+		return errors.New(integrityUsage)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// prints the emails to the standard output, for local development
+type LogMailer struct{}
+
+func (LogMailer) Send(to string, subject string, body string) error {
+	fmt.Printf("[MAIL] To: %s\n[MAIL] Subject: %s\n%s\n", to, subject, body)
+	return nil
+}
+
+// appends the emails to a file, for local development and testing
+type FileMailer struct {
+	Path string
+}
+
+var fileLock sync.Mutex
+
+func (m FileMailer) Send(to string, subject string, body string) error {
+	path := m.Path
+	if path == "" {
+		path = "mails.log"
+	}
+
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(
+		file,
+		"Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC1123Z),
+		to,
+		subject,
+		body,
+	)
+	return err
+}
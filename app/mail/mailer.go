@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+)
+
+// a Mailer sends plain text emails
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+var Client Mailer
+
+func Setup() error {
+	// choosing the mailer from the environment variables
+	// the log mailer is the default, so the app can run locally without smtp
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		Client = SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+	case "file":
+		Client = FileMailer{Path: os.Getenv("MAIL_FILE")}
+	case "", "log":
+		Client = LogMailer{}
+	default:
+		return fmt.Errorf("unknown mailer: %s", os.Getenv("MAILER"))
+	}
+
+	return nil
+}
+
+// returns the public url of the api, used in the links of the emails
+func backend() string {
+	if url := os.Getenv("BACKEND"); url != "" {
+		return url
+	}
+
+	return "http://localhost:" + os.Getenv("PORT")
+}
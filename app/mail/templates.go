@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+func SendVerification(to string, name string, token string) error {
+	link := backend() + "/api/v1/verify?token=" + url.QueryEscape(token)
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nPlease verify your email address by opening the link below:\n%s\n\nThe link expires in 24 hours. If you didn't register, you can ignore this email.",
+		name,
+		link,
+	)
+
+	return Client.Send(to, "Verify your email address", body)
+}
+
+func SendPasswordReset(to string, name string, token string) error {
+	link := os.Getenv("FRONTEND") + "/reset-password?token=" + url.QueryEscape(token)
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nYou can set a new password by opening the link below:\n%s\n\nThe link expires in 1 hour. If you didn't request a password reset, you can ignore this email.",
+		name,
+		link,
+	)
+
+	return Client.Send(to, "Reset your password", body)
+}
+
+func SendRegistrationNotice(to string, name string) error {
+	link := os.Getenv("FRONTEND")
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nSomeone tried to register with your email address, but you already have an account.\nIf it was you, you can log in or reset your password at the link below:\n%s\n\nIf it wasn't you, you can ignore this email.",
+		name,
+		link,
+	)
+
+	return Client.Send(to, "You already have an account", body)
+}
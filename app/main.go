@@ -3,10 +3,14 @@
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/0l1v3rr/todo/app/controller"
 	_ "github.com/0l1v3rr/todo/app/docs"
+	"github.com/0l1v3rr/todo/app/mail"
 	"github.com/0l1v3rr/todo/app/model"
+	"github.com/0l1v3rr/todo/app/oidc"
+	"github.com/0l1v3rr/todo/app/ratelimit"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -32,7 +36,27 @@ func main() {
 	// loading the environment variables
 	godotenv.Load(".env")
 
-	// connecting to the db
+	// running the migrate subcommand instead of the api
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrate(os.Args[2:])
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// running the integrity subcommand instead of the api
+	if len(os.Args) > 1 && os.Args[1] == "integrity" {
+		err := integrity(os.Args[2:])
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// connecting to the db and migrating it
 	err := model.Setup()
 	if err != nil {
 		fmt.Println("Failed to connect to the database: ")
@@ -40,39 +64,166 @@ func main() {
 		return
 	}
 
+	// setting up the mailer
+	err = mail.Setup()
+	if err != nil {
+		fmt.Println("Failed to set up the mailer: ")
+		fmt.Println(err.Error())
+		return
+	}
+
+	// setting up the oidc providers
+	err = oidc.Setup()
+	if err != nil {
+		fmt.Println("Failed to set up the OIDC providers: ")
+		fmt.Println(err.Error())
+		return
+	}
+
+	// the handlers reach the db through the stores
+	store := model.NewGormStore(model.DB)
+
+	// the rate limits are kept in memory, or in the db if there are more instances of the api
+	if os.Getenv("RATE_LIMIT_STORE") == "db" {
+		ratelimit.Setup(store)
+	}
+
+	h := &controller.Handler{
+		Users:        store,
+		Lists:        store,
+		Tasks:        store,
+		Trash:        store,
+		Sessions:     store,
+		Tokens:       store,
+		AccessTokens: store,
+		Tags:         store,
+		Items:        store,
+		Searcher:     store,
+	}
+
+	// setting up the trash and purging the expired part of it in the background
+	err = model.SetupTrash()
+	if err != nil {
+		fmt.Println("Failed to set up the trash: ")
+		fmt.Println(err.Error())
+		return
+	}
+	go store.PurgeTrashPeriodically()
+
 	// creating the gin router
 	r := gin.Default()
 
+	// the client ip is used by the rate limits and the sessions, so the X-Forwarded-For header
+	// is only accepted from the proxies listed in TRUSTED_PROXIES, and from none by default
+	err = r.SetTrustedProxies(trustedProxies())
+	if err != nil {
+		fmt.Println("Failed to set the trusted proxies: ")
+		fmt.Println(err.Error())
+		return
+	}
+
 	// using the cors
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{os.Getenv("FRONTEND")},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		ExposeHeaders:    []string{"Content-Length", "Link"},
 		AllowCredentials: true,
 	}))
 
+	// the endpoints anyone can use
+	public := r.Group("/api/v1")
+
+	// the endpoints of the logged-in users, every endpoint belongs here unless it has to be public
+	// the middleware resolves the user and checks the scopes of the personal access tokens
+	api := r.Group("/api/v1", h.Authenticate)
+
+	// auth endpoints
+	public.POST("/register", ratelimit.PerIp("register", controller.RegisterLimit), h.Register)
+	public.POST("/login", ratelimit.PerIp("login", controller.LoginLimit), h.Login)
+	public.POST("/login/2fa", ratelimit.PerIp("login", controller.LoginLimit), h.LoginTotp)
+	public.POST("/logout", h.Logout)
+	public.POST("/refresh", h.Refresh)
+	public.GET("/oidc/providers", h.GetOidcProviders)
+	public.GET("/oidc/:provider/login", h.OidcLogin)
+	public.GET("/oidc/:provider/callback", h.OidcCallback)
+	public.GET("/verify", h.VerifyEmail)
+	public.POST("/verify/resend", ratelimit.PerIp("email", controller.EmailLimit), h.ResendVerification)
+	public.POST("/password/forgot", ratelimit.PerIp("email", controller.EmailLimit), h.ForgotPassword)
+	public.POST("/password/reset", ratelimit.PerIp("reset", controller.EmailLimit), h.ResetPassword)
+
 	// user endpoints
-	r.GET("/api/v1/user", controller.GetLoggedInUser)
-	r.POST("/api/v1/register", controller.Register)
-	r.POST("/api/v1/login", controller.Login)
-	r.POST("/api/v1/logout", controller.Logout)
+	api.GET("/user", h.GetLoggedInUser)
+	api.PUT("/user", h.EditProfile)
+	api.PUT("/user/password", h.ChangePassword)
+	api.POST("/user/2fa", h.EnrollTotp)
+	api.POST("/user/2fa/confirm", h.ConfirmTotp)
+	api.DELETE("/user/2fa", h.DisableTotp)
+
+	// session endpoints
+	api.GET("/sessions", h.GetSessions)
+	api.DELETE("/sessions", h.RevokeAllSessions)
+	api.DELETE("/sessions/:id", h.RevokeSession)
+
+	// token endpoints
+	api.GET("/tokens", h.GetAccessTokens)
+	api.POST("/tokens", h.CreateAccessToken)
+	api.DELETE("/tokens/:id", h.DeleteAccessToken)
 
 	// task enpoints
-	r.GET("/api/v1/tasks/list/:listId", controller.GetTasksByListId)
-	r.GET("/api/v1/tasks/:url", controller.GetTaskByUrl)
-	r.POST("/api/v1/tasks", controller.CreateTask)
-	r.PATCH("/api/v1/tasks/:id", controller.ChangeTaskStatus)
-	r.PUT("/api/v1/tasks/:id", controller.EditTask)
-	r.DELETE("/api/v1/tasks/:id", controller.DeleteTask)
+	api.GET("/tasks", h.GetTasksByTags)
+	api.GET("/tasks/list/:listId", h.GetTasksByListId)
+	api.GET("/tasks/top", h.GetTopPriorityTasks)
+	api.GET("/tasks/due/:period", h.GetDueTasks)
+	api.GET("/tasks/:url", h.GetTaskByUrl)
+	api.GET("/tasks/:url/occurrences", h.GetTaskOccurrences)
+	api.POST("/tasks", h.CreateTask)
+	api.POST("/tasks/move", h.MoveTasks)
+	api.POST("/tasks/copy", h.CopyTasks)
+	api.PATCH("/tasks/:id", h.ChangeTaskStatus)
+	api.PUT("/tasks/:id", h.EditTask)
+	api.PUT("/tasks/:id/position", h.ReorderTask)
+	api.DELETE("/tasks/:id", h.DeleteTask)
+
+	// checklist item endpoints
+	api.GET("/tasks/:url/items", h.GetItems)
+	api.POST("/tasks/:id/items", h.CreateItem)
+	api.PUT("/tasks/:id/items/:itemId", h.EditItem)
+	api.DELETE("/tasks/:id/items/:itemId", h.DeleteItem)
 
 	// list endpoints
-	r.GET("/api/v1/lists/user/:userId", controller.GetListsByUserId)
-	r.GET("/api/v1/lists/:url", controller.GetListByUrl)
-	r.POST("/api/v1/lists", controller.CreateList)
+	api.GET("/lists/user/:userId", h.GetListsByUserId)
+	api.GET("/lists/:url", h.GetListByUrl)
+	api.POST("/lists", h.CreateList)
+	api.PUT("/lists/:id", h.EditList)
+	api.PUT("/lists/:id/position", h.ReorderList)
+	api.DELETE("/lists/:id", h.DeleteList)
+
+	// tag endpoints
+	api.GET("/tags", h.GetTags)
+	api.POST("/tags", h.CreateTag)
+	api.PUT("/tags/:id", h.EditTag)
+	api.POST("/tags/:id/merge", h.MergeTag)
+	api.DELETE("/tags/:id", h.DeleteTag)
+
+	// member endpoints
+	api.GET("/members/list/:listId", h.GetMembersByListId)
+	api.POST("/members", h.InviteMember)
+	api.PUT("/members/:id", h.EditMember)
+	api.DELETE("/members/:id", h.RemoveMember)
+
+	// search endpoints
+	api.GET("/search", h.Search)
+
+	// trash endpoints
+	api.GET("/trash", h.GetTrash)
+	api.POST("/trash/tasks/:id/restore", h.RestoreTask)
+	api.DELETE("/trash/tasks/:id", h.PurgeTask)
+	api.POST("/trash/lists/:id/restore", h.RestoreList)
+	api.DELETE("/trash/lists/:id", h.PurgeList)
 
 	// file endpoints
-	r.POST("/api/v1/files", controller.UploadFile)
+	api.POST("/files", ratelimit.PerIp("upload", controller.UploadLimit), h.UploadFile)
 
 	// serving the static images
 	r.Static("/assets/images", "./images")
@@ -83,3 +234,16 @@ func main() {
 	// running the router
 	r.Run(fmt.Sprintf(":%s", os.Getenv("PORT")))
 }
+
+// returns the comma-separated ips and cidr ranges of TRUSTED_PROXIES,
+// nil means that no proxy is trusted and the ip of the connection is used
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
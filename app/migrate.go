@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0l1v3rr/todo/app/migration"
+	"github.com/0l1v3rr/todo/app/model"
+)
+
+const migrateUsage = "Usage: todo-backend migrate up|down|status"
+
+// the migrate subcommand, which changes the schema without starting the api
+func migrate(args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	// connecting to the db without migrating it
+	err := model.Connect()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migration.Up(model.DB)
+		for _, m := range applied {
+			fmt.Printf("applied %d: %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("the schema is up to date")
+		}
+	case "down":
+		reverted, err := migration.Down(model.DB)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("reverted %d: %s\n", reverted.Version, reverted.Name)
+	case "status":
+		states, err := migration.Status(model.DB)
+		if err != nil {
+			return err
+		}
+
+		for _, state := range states {
+			status := "pending"
+			if state.AppliedAt != nil {
+				status = "applied at " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if state.Version > migration.Latest() {
+				status += ", unknown to this build"
+			}
+
+			fmt.Printf("%4d  %-30s %s\n", state.Version, state.Name, status)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the tables of the app before the migrations were introduced
+// the structs are copies of the models at that time, so the later changes of the models don't change this migration
+// AutoMigrate only creates what's missing, so the databases created before keep their data
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "create the initial schema",
+	Up: func(tx *gorm.DB) error {
+		type User struct {
+			Id           int    `gorm:"primaryKey"`
+			Name         string `gorm:"not null"`
+			Email        string `gorm:"not null;unique"`
+			Password     string `gorm:"not null;column:password"`
+			IsEnabled    bool   `gorm:"not null;column:is_enabled"`
+			AvatarUrl    string `gorm:"column:avatar_url"`
+			PendingEmail string `gorm:"column:pending_email"`
+			TotpEnabled  bool   `gorm:"not null;default:false"`
+			TotpSecret   string `gorm:"size:64"`
+			TotpLastStep int64  `gorm:"not null;default:0"`
+		}
+
+		type Task struct {
+			Id          int    `gorm:"primaryKey"`
+			ListId      int    `gorm:"not null;column:list_id"`
+			CreatedById int    `gorm:"not null;column:created_by_id"`
+			Title       string `gorm:"not null"`
+			Url         string `gorm:"not null;unique"`
+			Description string
+			IsDone      bool       `gorm:"not null;column:is_done"`
+			CreatedAt   time.Time  `gorm:"not null;column:created_at"`
+			StartDate   *time.Time `gorm:"column:start_date"`
+			DueDate     *time.Time `gorm:"column:due_date;index"`
+			Recurrence  string
+			Priority    int     `gorm:"not null;default:0;index"`
+			Position    float64 `gorm:"not null;default:0"`
+		}
+
+		type List struct {
+			Id       int     `gorm:"primaryKey"`
+			OwnerId  int     `gorm:"not null;column:owner_id"`
+			ImageUrl string  `gorm:"column:image_url"`
+			Name     string  `gorm:"not null"`
+			Url      string  `gorm:"unique"`
+			Position float64 `gorm:"not null;default:0"`
+		}
+
+		type Member struct {
+			Id       int     `gorm:"primaryKey"`
+			ListId   int     `gorm:"not null;column:list_id;uniqueIndex:idx_member_list_user"`
+			UserId   int     `gorm:"not null;column:user_id;uniqueIndex:idx_member_list_user"`
+			Role     string  `gorm:"not null"`
+			Position float64 `gorm:"not null;default:0"`
+		}
+
+		type Item struct {
+			Id       int    `gorm:"primaryKey"`
+			TaskId   int    `gorm:"not null;column:task_id;index"`
+			Title    string `gorm:"not null"`
+			IsDone   bool   `gorm:"not null;column:is_done"`
+			Position int    `gorm:"not null"`
+		}
+
+		type Tag struct {
+			Id      int    `gorm:"primaryKey"`
+			OwnerId int    `gorm:"not null;column:owner_id;uniqueIndex:idx_tag_owner_name"`
+			Name    string `gorm:"not null;size:32;uniqueIndex:idx_tag_owner_name"`
+			Color   string `gorm:"not null;size:7"`
+		}
+
+		type TaskTag struct {
+			TaskId int `gorm:"primaryKey;column:task_id"`
+			TagId  int `gorm:"primaryKey;column:tag_id"`
+		}
+
+		type Token struct {
+			Id        int        `gorm:"primaryKey"`
+			UserId    int        `gorm:"not null;index"`
+			Purpose   string     `gorm:"not null;size:16"`
+			Hash      string     `gorm:"not null;size:64;uniqueIndex"`
+			ExpiresAt time.Time  `gorm:"not null"`
+			UsedAt    *time.Time `gorm:"default:null"`
+			Attempts  int        `gorm:"not null;default:0"`
+			CreatedAt time.Time  `gorm:"not null"`
+		}
+
+		type Session struct {
+			Id           int        `gorm:"primaryKey"`
+			UserId       int        `gorm:"not null;index"`
+			RefreshHash  string     `gorm:"not null;size:64;uniqueIndex"`
+			PreviousHash string     `gorm:"size:64;index"`
+			Device       string     `gorm:"size:255"`
+			Ip           string     `gorm:"size:45"`
+			CreatedAt    time.Time  `gorm:"not null"`
+			LastSeenAt   time.Time  `gorm:"not null"`
+			ExpiresAt    time.Time  `gorm:"not null"`
+			RevokedAt    *time.Time `gorm:"default:null"`
+		}
+
+		type AccessToken struct {
+			Id         int        `gorm:"primaryKey"`
+			UserId     int        `gorm:"not null;index"`
+			Name       string     `gorm:"not null;size:64"`
+			Prefix     string     `gorm:"not null;size:16"`
+			Hash       string     `gorm:"not null;size:64;uniqueIndex"`
+			ScopeList  string     `gorm:"column:scopes;not null"`
+			ExpiresAt  *time.Time `gorm:"default:null"`
+			LastUsedAt *time.Time `gorm:"default:null"`
+			CreatedAt  time.Time  `gorm:"not null"`
+		}
+
+		type Identity struct {
+			Id       int    `gorm:"primaryKey"`
+			UserId   int    `gorm:"not null;index"`
+			Provider string `gorm:"not null;size:64;uniqueIndex:idx_identities_provider_subject"`
+			Subject  string `gorm:"not null;size:255;uniqueIndex:idx_identities_provider_subject"`
+		}
+
+		type RecoveryCode struct {
+			Id     int        `gorm:"primaryKey"`
+			UserId int        `gorm:"not null;index"`
+			Hash   string     `gorm:"not null;size:64"`
+			UsedAt *time.Time `gorm:"default:null"`
+		}
+
+		type RateLimit struct {
+			Key           string     `gorm:"primaryKey;column:limit_key;size:191"`
+			Tokens        float64    `gorm:"not null;default:0"`
+			RefilledAt    time.Time  `gorm:"not null"`
+			Failures      int        `gorm:"not null;default:0"`
+			LastFailureAt *time.Time `gorm:"default:null"`
+			LockedUntil   *time.Time `gorm:"default:null"`
+		}
+
+		return tx.AutoMigrate(
+			&User{}, &Task{}, &List{}, &Member{}, &Item{}, &Tag{}, &TaskTag{},
+			&Token{}, &Session{}, &AccessToken{}, &Identity{}, &RecoveryCode{}, &RateLimit{},
+		)
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(
+			"rate_limits", "recovery_codes", "identities", "access_tokens", "sessions", "tokens",
+			"task_tags", "tags", "items", "members", "lists", "tasks", "users",
+		)
+	},
+}
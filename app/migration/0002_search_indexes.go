@@ -0,0 +1,39 @@
+package migration
+
+import "gorm.io/gorm"
+
+// the FULLTEXT indexes of the search, the other databases search with LIKE
+var searchIndexes = Migration{
+	Version: 2,
+	Name:    "create the search indexes",
+	Up: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "mysql" {
+			return nil
+		}
+
+		if !tx.Migrator().HasIndex("tasks", "idx_tasks_search") {
+			if err := tx.Exec("CREATE FULLTEXT INDEX idx_tasks_search ON tasks (title, description)").Error; err != nil {
+				return err
+			}
+		}
+
+		if !tx.Migrator().HasIndex("lists", "idx_lists_search") {
+			if err := tx.Exec("CREATE FULLTEXT INDEX idx_lists_search ON lists (name)").Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "mysql" {
+			return nil
+		}
+
+		if err := tx.Exec("DROP INDEX idx_tasks_search ON tasks").Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("DROP INDEX idx_lists_search ON lists").Error
+	},
+}
@@ -0,0 +1,181 @@
+package migration
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// the structs only have the keys and the associations the foreign keys are created from
+type fkUser struct {
+	Id int `gorm:"primaryKey"`
+}
+
+type fkList struct {
+	Id      int     `gorm:"primaryKey"`
+	OwnerId int     `gorm:"not null;column:owner_id"`
+	Owner   *fkUser `gorm:"foreignKey:OwnerId;constraint:OnDelete:CASCADE"`
+}
+
+type fkTask struct {
+	Id          int     `gorm:"primaryKey"`
+	ListId      int     `gorm:"not null;column:list_id"`
+	List        *fkList `gorm:"foreignKey:ListId;constraint:OnDelete:CASCADE"`
+	CreatedById *int    `gorm:"column:created_by_id"`
+	CreatedBy   *fkUser `gorm:"foreignKey:CreatedById;constraint:OnDelete:SET NULL"`
+}
+
+type fkMember struct {
+	Id     int     `gorm:"primaryKey"`
+	ListId int     `gorm:"not null;column:list_id"`
+	List   *fkList `gorm:"foreignKey:ListId;constraint:OnDelete:CASCADE"`
+	UserId int     `gorm:"not null;column:user_id"`
+	User   *fkUser `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE"`
+}
+
+type fkItem struct {
+	Id     int     `gorm:"primaryKey"`
+	TaskId int     `gorm:"not null;column:task_id"`
+	Task   *fkTask `gorm:"foreignKey:TaskId;constraint:OnDelete:CASCADE"`
+}
+
+type fkTag struct {
+	Id      int     `gorm:"primaryKey"`
+	OwnerId int     `gorm:"not null;column:owner_id"`
+	Owner   *fkUser `gorm:"foreignKey:OwnerId;constraint:OnDelete:CASCADE"`
+}
+
+type fkTaskTag struct {
+	TaskId int     `gorm:"primaryKey;column:task_id"`
+	Task   *fkTask `gorm:"foreignKey:TaskId;constraint:OnDelete:CASCADE"`
+	TagId  int     `gorm:"primaryKey;column:tag_id"`
+	Tag    *fkTag  `gorm:"foreignKey:TagId;constraint:OnDelete:CASCADE"`
+}
+
+// the tables of the login, which are deleted with their user
+type fkUserRow struct {
+	Id     int     `gorm:"primaryKey"`
+	UserId int     `gorm:"not null"`
+	User   *fkUser `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE"`
+}
+
+func (fkUser) TableName() string    { return "users" }
+func (fkList) TableName() string    { return "lists" }
+func (fkTask) TableName() string    { return "tasks" }
+func (fkMember) TableName() string  { return "members" }
+func (fkItem) TableName() string    { return "items" }
+func (fkTag) TableName() string     { return "tags" }
+func (fkTaskTag) TableName() string { return "task_tags" }
+
+// a foreign key of a table, created from the association of the struct
+type foreignKey struct {
+	Table       string
+	Model       interface{}
+	Association string
+}
+
+// the parents get their foreign keys first
+var foreignKeyList = []foreignKey{
+	{"lists", &fkList{}, "Owner"},
+	{"tags", &fkTag{}, "Owner"},
+	{"tokens", &fkUserRow{}, "User"},
+	{"sessions", &fkUserRow{}, "User"},
+	{"access_tokens", &fkUserRow{}, "User"},
+	{"identities", &fkUserRow{}, "User"},
+	{"recovery_codes", &fkUserRow{}, "User"},
+	{"tasks", &fkTask{}, "List"},
+	{"tasks", &fkTask{}, "CreatedBy"},
+	{"members", &fkMember{}, "List"},
+	{"members", &fkMember{}, "User"},
+	{"items", &fkItem{}, "Task"},
+	{"task_tags", &fkTaskTag{}, "Task"},
+	{"task_tags", &fkTaskTag{}, "Tag"},
+}
+
+// the foreign keys between the tables
+// the rows are deleted with their parents, except the tasks, which keep existing without
+// their creator, so their created_by_id becomes nullable
+var foreignKeys = Migration{
+	Version: 3,
+	Name:    "add the foreign keys",
+	Up: func(tx *gorm.DB) error {
+		// the foreign keys can't be created while rows reference missing parents
+		orphans, err := CheckIntegrity(tx)
+		if err != nil {
+			return err
+		}
+		if len(orphans) > 0 {
+			return errors.New("the database has rows referencing missing rows, they can be listed with `todo-backend integrity check` and removed with `todo-backend integrity repair`")
+		}
+
+		err = keepIndexes(tx, "tasks", func() error {
+			return tx.Migrator().AlterColumn(&fkTask{}, "CreatedById")
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, fk := range foreignKeyList {
+			err := keepIndexes(tx, fk.Table, func() error {
+				return tx.Table(fk.Table).Migrator().CreateConstraint(fk.Model, fk.Association)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		for i := len(foreignKeyList) - 1; i >= 0; i-- {
+			fk := foreignKeyList[i]
+			err := keepIndexes(tx, fk.Table, func() error {
+				return tx.Table(fk.Table).Migrator().DropConstraint(fk.Model, fk.Association)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// the tasks without a creator are given to the owner of their list
+		err := tx.Exec("UPDATE tasks SET created_by_id = " + listOwner + " WHERE created_by_id IS NULL").Error
+		if err != nil {
+			return err
+		}
+
+		type Task struct {
+			Id          int `gorm:"primaryKey"`
+			CreatedById int `gorm:"not null;column:created_by_id"`
+		}
+
+		return keepIndexes(tx, "tasks", func() error {
+			return tx.Migrator().AlterColumn(&Task{}, "CreatedById")
+		})
+	},
+}
+
+// sqlite changes a table by recreating it, which drops its indexes,
+// so they are saved before the change and created again after it
+func keepIndexes(tx *gorm.DB, table string, change func() error) error {
+	if tx.Dialector.Name() != "sqlite" {
+		return change()
+	}
+
+	var indexes []string
+	err := tx.Raw("SELECT sql FROM sqlite_master WHERE type = ? AND tbl_name = ? AND sql IS NOT NULL", "index", table).Scan(&indexes).Error
+	if err != nil {
+		return err
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if err := tx.Exec(index).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
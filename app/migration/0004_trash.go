@@ -0,0 +1,95 @@
+package migration
+
+import "gorm.io/gorm"
+
+// the columns of the trash
+type trashList struct {
+	Id        int            `gorm:"primaryKey"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type trashTask struct {
+	Id              int            `gorm:"primaryKey"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+	DeletedWithList bool           `gorm:"not null;default:false"`
+}
+
+func (trashList) TableName() string { return "lists" }
+func (trashTask) TableName() string { return "tasks" }
+
+// the deleted tasks and lists are kept in the trash until they are restored or purged
+var trash = Migration{
+	Version: 4,
+	Name:    "add the trash",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+
+		for _, column := range []struct {
+			Model interface{}
+			Field string
+		}{
+			{&trashList{}, "DeletedAt"},
+			{&trashTask{}, "DeletedAt"},
+			{&trashTask{}, "DeletedWithList"},
+		} {
+			if err := m.AddColumn(column.Model, column.Field); err != nil {
+				return err
+			}
+		}
+
+		if err := m.CreateIndex(&trashList{}, "DeletedAt"); err != nil {
+			return err
+		}
+
+		return m.CreateIndex(&trashTask{}, "DeletedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		// the trash is emptied first, otherwise the deleted rows would come back
+		// the rows are deleted one table at a time, because sqlite runs the migrations without the foreign keys
+		deletedLists := tx.Table("lists").Select("id").Where("deleted_at IS NOT NULL")
+		deletedTasks := tx.Table("tasks").Select("id").Where("deleted_at IS NOT NULL OR list_id IN (?)", deletedLists)
+
+		for _, table := range []string{"items", "task_tags"} {
+			if err := tx.Table(table).Where("task_id IN (?)", deletedTasks).Delete(nil).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Table("members").Where("list_id IN (?)", deletedLists).Delete(nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Table("tasks").Where("deleted_at IS NOT NULL OR list_id IN (?)", deletedLists).Delete(nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Table("lists").Where("deleted_at IS NOT NULL").Delete(nil).Error; err != nil {
+			return err
+		}
+
+		m := tx.Migrator()
+
+		if err := m.DropIndex(&trashList{}, "DeletedAt"); err != nil {
+			return err
+		}
+
+		if err := m.DropIndex(&trashTask{}, "DeletedAt"); err != nil {
+			return err
+		}
+
+		err := keepIndexes(tx, "lists", func() error {
+			return m.DropColumn(&trashList{}, "DeletedAt")
+		})
+		if err != nil {
+			return err
+		}
+
+		return keepIndexes(tx, "tasks", func() error {
+			if err := m.DropColumn(&trashTask{}, "DeletedAt"); err != nil {
+				return err
+			}
+
+			return m.DropColumn(&trashTask{}, "DeletedWithList")
+		})
+	},
+}
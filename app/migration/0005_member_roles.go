@@ -0,0 +1,17 @@
+package migration
+
+import "gorm.io/gorm"
+
+// the members can't have the owner role anymore, because they could delete a list they can't restore
+// the members who had it become editors
+var memberRoles = Migration{
+	Version: 5,
+	Name:    "make the owner members editors",
+	Up: func(tx *gorm.DB) error {
+		return tx.Table("members").Where("role = ?", "owner").Update("role", "editor").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		// the old roles aren't known anymore, and the editors can stay editors
+		return nil
+	},
+}
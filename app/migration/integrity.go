@@ -0,0 +1,114 @@
+package migration
+
+import "gorm.io/gorm"
+
+// a column referencing the id of a parent table
+// the rows of a missing parent are deleted, or their column is changed to Reassign if it's set
+type relation struct {
+	Table    string
+	Column   string
+	Parent   string
+	Reassign string
+}
+
+// the tasks of a missing user are given to the owner of their list
+const listOwner = "(SELECT owner_id FROM lists WHERE lists.id = tasks.list_id)"
+
+// the references between the tables, the same as the foreign keys
+var relations = []relation{
+	{Table: "lists", Column: "owner_id", Parent: "users"},
+	{Table: "tasks", Column: "list_id", Parent: "lists"},
+	{Table: "tasks", Column: "created_by_id", Parent: "users", Reassign: listOwner},
+	{Table: "members", Column: "list_id", Parent: "lists"},
+	{Table: "members", Column: "user_id", Parent: "users"},
+	{Table: "items", Column: "task_id", Parent: "tasks"},
+	{Table: "tags", Column: "owner_id", Parent: "users"},
+	{Table: "task_tags", Column: "task_id", Parent: "tasks"},
+	{Table: "task_tags", Column: "tag_id", Parent: "tags"},
+	{Table: "tokens", Column: "user_id", Parent: "users"},
+	{Table: "sessions", Column: "user_id", Parent: "users"},
+	{Table: "access_tokens", Column: "user_id", Parent: "users"},
+	{Table: "identities", Column: "user_id", Parent: "users"},
+	{Table: "recovery_codes", Column: "user_id", Parent: "users"},
+}
+
+// the number of rows of a table referencing a missing parent
+type Orphans struct {
+	Table      string
+	Column     string
+	Parent     string
+	Reassigned bool
+	Count      int64
+}
+
+// returns the rows referencing missing parents
+func CheckIntegrity(db *gorm.DB) ([]Orphans, error) {
+	found := []Orphans{}
+
+	for _, r := range relations {
+		var count int64
+		if err := orphaned(db, r).Count(&count).Error; err != nil {
+			return nil, err
+		}
+
+		if count > 0 {
+			found = append(found, Orphans{Table: r.Table, Column: r.Column, Parent: r.Parent, Reassigned: r.Reassign != "", Count: count})
+		}
+	}
+
+	return found, nil
+}
+
+// deletes the rows referencing missing parents, or reassigns them, and returns what was changed
+func RepairIntegrity(db *gorm.DB) ([]Orphans, error) {
+	repaired := []Orphans{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// deleting a row can leave its own children orphaned, so it's repeated until nothing changes
+		for changed := true; changed; {
+			changed = false
+
+			for _, r := range relations {
+				var res *gorm.DB
+				if r.Reassign != "" {
+					res = orphaned(tx, r).Update(r.Column, gorm.Expr(r.Reassign))
+				} else {
+					res = orphaned(tx, r).Delete(nil)
+				}
+				if res.Error != nil {
+					return res.Error
+				}
+
+				if res.RowsAffected > 0 {
+					changed = true
+					repaired = addOrphans(repaired, Orphans{Table: r.Table, Column: r.Column, Parent: r.Parent, Reassigned: r.Reassign != "", Count: res.RowsAffected})
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repaired, nil
+}
+
+// selects the rows of the relation referencing a missing parent
+func orphaned(db *gorm.DB, r relation) *gorm.DB {
+	parents := db.Table(r.Parent).Select("id")
+	return db.Table(r.Table).Where(r.Column+" IS NOT NULL AND "+r.Column+" NOT IN (?)", parents)
+}
+
+// adds the count to the orphans of the same relation
+func addOrphans(list []Orphans, orphans Orphans) []Orphans {
+	for i := range list {
+		if list[i].Table == orphans.Table && list[i].Column == orphans.Column {
+			list[i].Count += orphans.Count
+			return list
+		}
+	}
+
+	return append(list, orphans)
+}
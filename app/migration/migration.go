@@ -0,0 +1,205 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// a numbered change of the schema, Down reverts what Up did
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// a row of the migrations table, one for every applied migration
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// the state of a migration, AppliedAt is nil if it's pending
+type State struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// every migration of the app, in the order of their versions
+// a released migration must never be changed, the changes of the schema go to a new one
+var migrations = []Migration{
+	initialSchema,
+	searchIndexes,
+	foreignKeys,
+	trash,
+	memberRoles,
+}
+
+// returns the version of the newest migration of this build
+func Latest() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// applies the pending migrations in order
+func Up(db *gorm.DB) ([]Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(applied); err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		// the migration and its row are saved together
+		// MySQL commits the schema changes immediately, so a failed migration may need a manual cleanup there
+		err := transaction(db, func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// reverts the last applied migration
+func Down(db *gorm.DB) (Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	if err := checkVersion(applied); err != nil {
+		return Migration{}, err
+	}
+
+	// finding the applied migration with the highest version
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := transaction(db, func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return Migration{}, fmt.Errorf("reverting migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+
+		return migration, nil
+	}
+
+	return Migration{}, fmt.Errorf("there are no applied migrations")
+}
+
+// returns the state of every migration, including the applied ones this build doesn't know
+func Status(db *gorm.DB) ([]State, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []State{}
+	for _, migration := range migrations {
+		state := State{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			state.AppliedAt = &row.AppliedAt
+		}
+
+		states = append(states, state)
+	}
+
+	for _, row := range applied {
+		if row.Version > Latest() {
+			row := row
+			states = append(states, State{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt})
+		}
+	}
+
+	return states, nil
+}
+
+// returns an error if the database was migrated by a newer version of the app
+func Check(db *gorm.DB) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	return checkVersion(applied)
+}
+
+func checkVersion(applied map[int]SchemaMigration) error {
+	for version := range applied {
+		if version > Latest() {
+			return fmt.Errorf("the database schema is at version %d, but this build only knows the versions up to %d", version, Latest())
+		}
+	}
+
+	return nil
+}
+
+// runs a migration in a transaction
+// sqlite changes the constraints by recreating the tables, and dropping a table would
+// delete the rows referencing it, so its foreign keys are turned off during the migration
+func transaction(db *gorm.DB, migrate func(tx *gorm.DB) error) error {
+	if db.Dialector.Name() != "sqlite" {
+		return db.Transaction(migrate)
+	}
+
+	// the pragma only affects one connection, and it can't be changed inside a transaction
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("PRAGMA foreign_keys = ON")
+
+		return conn.Transaction(migrate)
+	})
+}
+
+// returns the rows of the migrations table by version, creating the table if it doesn't exist
+func appliedMigrations(db *gorm.DB) (map[int]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := map[int]SchemaMigration{}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,204 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+)
+
+// the scopes of the personal access tokens
+// every token can read, the write scopes allow changes
+const (
+	ScopeRead       = "read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeListsWrite = "lists:write"
+)
+
+// the prefix of the personal access tokens, so they are easy to recognize
+const accessTokenPrefix = "todo_"
+
+var ErrInvalidAccessToken = errors.New("invalid or expired access token")
+
+// defining a model struct
+// only the hash of the token is stored in the db
+type AccessToken struct {
+	Id         int        `json:"id" gorm:"primaryKey" example:"1"`
+	UserId     int        `json:"-" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null;size:64" example:"Backup script"`
+	Prefix     string     `json:"prefix" gorm:"not null;size:16" example:"todo_Xq3f"`
+	Hash       string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	ScopeList  string     `json:"-" gorm:"column:scopes;not null"`
+	Scopes     []string   `json:"scopes" gorm:"-" example:"read,tasks:write"`
+	ExpiresAt  *time.Time `json:"expiresAt" gorm:"default:null" example:"2023-01-01T00:00:00Z"`
+	LastUsedAt *time.Time `json:"lastUsedAt" gorm:"default:null" example:"2022-07-21T08:12:45Z"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"not null" example:"2022-07-20T16:38:19Z"`
+
+	// the raw token, only returned when the token is created
+	Token string `json:"token,omitempty" gorm:"-" example:"todo_Xq3fK0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"`
+}
+
+// defining a NewAccessToken for the documentation
+type NewAccessToken struct {
+	Name      string     `json:"name" example:"Backup script"`
+	Scopes    []string   `json:"scopes" example:"read,tasks:write"`
+	ExpiresAt *time.Time `json:"expiresAt" example:"2023-01-01T00:00:00Z"`
+}
+
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeTasksWrite || scope == ScopeListsWrite
+}
+
+func (token NewAccessToken) Validate() (bool, string) {
+	// if the name is empty
+	if strings.TrimSpace(token.Name) == "" {
+		return false, "Please provide a name for the token."
+	}
+
+	// if the name is too long
+	if len(token.Name) > 64 {
+		return false, "The name can be maximum 64 characters long."
+	}
+
+	// if there are no scopes
+	if len(token.Scopes) == 0 {
+		return false, "Please provide at least one scope."
+	}
+
+	// if a scope is invalid or duplicated
+	seen := map[string]bool{}
+	for _, scope := range token.Scopes {
+		if !IsValidScope(scope) {
+			return false, "The scope has to be read, tasks:write or lists:write."
+		}
+		if seen[scope] {
+			return false, "Every scope can only be specified once."
+		}
+		seen[scope] = true
+	}
+
+	// if the token would already be expired
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return false, "The expiry date has to be in the future."
+	}
+
+	return true, ""
+}
+
+func (token AccessToken) HasScope(scope string) bool {
+	for _, s := range token.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns the tokens of the user, the newest first
+func (s *GormStore) GetAccessTokens(userId int) ([]AccessToken, error) {
+	var tokens []AccessToken
+	tx := s.db.Where("user_id = ?", userId).Order("id DESC").Find(&tokens)
+
+	for i := range tokens {
+		tokens[i].Scopes = strings.Split(tokens[i].ScopeList, ",")
+	}
+
+	return tokens, tx.Error
+}
+
+// creates a new token for the user and returns it with the raw value
+func (s *GormStore) CreateAccessToken(userId int, create NewAccessToken) (AccessToken, error) {
+	raw, err := util.GenerateToken()
+	if err != nil {
+		return AccessToken{}, err
+	}
+	raw = accessTokenPrefix + raw
+
+	token := AccessToken{
+		UserId:    userId,
+		Name:      strings.TrimSpace(create.Name),
+		Prefix:    raw[:len(accessTokenPrefix)+4],
+		Hash:      util.HashToken(raw),
+		ScopeList: strings.Join(create.Scopes, ","),
+		Scopes:    create.Scopes,
+		ExpiresAt: util.ToUTC(create.ExpiresAt),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	tx := s.db.Create(&token)
+	token.Token = raw
+	return token, tx.Error
+}
+
+// deletes a token of the user
+func (s *GormStore) DeleteAccessToken(userId int, id int) (bool, error) {
+	tx := s.db.Where("id = ? AND user_id = ?", id, userId).Delete(&AccessToken{})
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// returns the token with the raw value, if it's valid
+// the last-used time is updated at most once every minute
+func (s *GormStore) AuthenticateAccessToken(raw string) (AccessToken, error) {
+	if !strings.HasPrefix(raw, accessTokenPrefix) {
+		return AccessToken{}, ErrInvalidAccessToken
+	}
+
+	var token AccessToken
+	tx := s.db.Where("hash = ?", util.HashToken(raw)).Limit(1).Find(&token)
+	if tx.Error != nil {
+		return AccessToken{}, tx.Error
+	}
+
+	now := time.Now().UTC()
+	if tx.RowsAffected == 0 || (token.ExpiresAt != nil && !token.ExpiresAt.After(now)) {
+		return AccessToken{}, ErrInvalidAccessToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastSeenInterval {
+		s.db.Model(&AccessToken{}).Where("id = ?", token.Id).Update("last_used_at", now)
+		token.LastUsedAt = &now
+	}
+
+	token.Scopes = strings.Split(token.ScopeList, ",")
+	return token, nil
+}
+
+// returns the token of a bearer authorization header
+func BearerToken(header string) (string, bool) {
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[7:]), true
+}
+
+// returns the scope a token needs for the request
+// false means that the endpoint can't be used with a token
+func RequiredScope(method string, path string) (string, bool) {
+	// the sessions and the tokens can only be managed after logging in
+	for _, prefix := range []string{"/api/v1/sessions", "/api/v1/tokens"} {
+		if strings.HasPrefix(path, prefix) {
+			return "", false
+		}
+	}
+
+	if method == "GET" || method == "HEAD" {
+		return ScopeRead, true
+	}
+
+	// the same goes for changing the account and uploading files
+	if strings.HasPrefix(path, "/api/v1/user") || strings.HasPrefix(path, "/api/v1/files") {
+		return "", false
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/api/v1/tasks"), strings.HasPrefix(path, "/api/v1/tags"), strings.HasPrefix(path, "/api/v1/trash/tasks"):
+		return ScopeTasksWrite, true
+	case strings.HasPrefix(path, "/api/v1/lists"), strings.HasPrefix(path, "/api/v1/members"), strings.HasPrefix(path, "/api/v1/trash/lists"):
+		return ScopeListsWrite, true
+	}
+
+	return "", false
+}
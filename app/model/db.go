@@ -3,35 +3,89 @@
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/0l1v3rr/todo/app/migration"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
+// connects to the db and migrates its schema to the newest version
 func Setup() error {
-	// creating the dsn from the environment variables
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local",
-		os.Getenv("MYSQL_USERNAME"),
-		os.Getenv("MYSQL_PASSWORD"),
-		os.Getenv("MYSQL_DOMAIN"),
-		os.Getenv("MYSQL_PORT"),
-		os.Getenv("MYSQL_DATABASE"),
-	)
-
-	// opening a gorm connection
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	err := Connect()
 	if err != nil {
 		return err
 	}
 
-	// migrating the models
-	DB.AutoMigrate(&User{})
-	DB.AutoMigrate(&Task{})
-	DB.AutoMigrate(&List{})
+	// using the TaskTag model as the join table of the task tags
+	err = DB.SetupJoinTable(&Task{}, "Tags", &TaskTag{})
+	if err != nil {
+		return err
+	}
+
+	// applying the pending migrations, this fails if the schema is newer than the app
+	_, err = migration.Up(DB)
+	return err
+}
 
-	return nil
+// opens a gorm connection with the configured driver
+func Connect() error {
+	dialector, err := dialector()
+	if err != nil {
+		return err
+	}
+
+	DB, err = gorm.Open(dialector, &gorm.Config{})
+	return err
+}
+
+// returns the dialector of the DB_DRIVER with the DATABASE_URL
+func dialector() (gorm.Dialector, error) {
+	url := os.Getenv("DATABASE_URL")
+
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "mysql":
+		// the dsn is created from the MYSQL_ variables if there's no url
+		if url == "" {
+			url = fmt.Sprintf(
+				"%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local",
+				os.Getenv("MYSQL_USERNAME"),
+				os.Getenv("MYSQL_PASSWORD"),
+				os.Getenv("MYSQL_DOMAIN"),
+				os.Getenv("MYSQL_PORT"),
+				os.Getenv("MYSQL_DATABASE"),
+			)
+		}
+		return mysql.Open(url), nil
+	case "postgres":
+		if url == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required by the postgres driver")
+		}
+		return postgres.Open(url), nil
+	case "sqlite":
+		if url == "" {
+			url = "todo.db"
+		}
+
+		// waiting for the lock instead of failing when two requests write at the same time,
+		// and turning on the foreign keys, which sqlite ignores by default
+		for _, pragma := range []string{"busy_timeout(5000)", "foreign_keys(1)"} {
+			if strings.Contains(url, pragma[:strings.Index(pragma, "(")]) {
+				continue
+			}
+
+			separator := "?"
+			if strings.Contains(url, "?") {
+				separator = "&"
+			}
+			url += separator + "_pragma=" + pragma
+		}
+		return sqlite.Dialector{DriverName: sqliteDriver, DSN: url}, nil
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q, it can be mysql, postgres or sqlite", driver)
+	}
 }
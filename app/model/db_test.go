@@ -0,0 +1,210 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// returns a store on a new in-memory sqlite db, migrated by Setup like the real one
+func newTestStore(t *testing.T) *GormStore {
+	t.Helper()
+
+	// every test gets its own db, which is shared by the connections of the pool
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", "file:"+name+"?mode=memory&cache=shared")
+
+	if err := Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewGormStore(DB)
+}
+
+// creates an enabled user, without the slow password hashing of Register
+func createTestUser(t *testing.T, s *GormStore, name string) User {
+	t.Helper()
+
+	user := User{Name: name, Email: strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com", IsEnabled: true}
+	if err := s.db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+func createTestList(t *testing.T, s *GormStore, ownerId int, name string) List {
+	t.Helper()
+
+	list, err := s.CreateList(List{OwnerId: ownerId, Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return list
+}
+
+func createTestTask(t *testing.T, s *GormStore, listId int, createdById int, title string) Task {
+	t.Helper()
+
+	task, err := s.CreateTask(Task{ListId: listId, CreatedById: &createdById, Title: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return task
+}
+
+func taskIds(tasks []Task) []int {
+	ids := []int{}
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+
+	return ids
+}
+
+// counts the rows of the model matching the condition, including the ones in the trash
+func countRows(t *testing.T, s *GormStore, model interface{}, query string, args ...interface{}) int64 {
+	t.Helper()
+
+	var count int64
+	if err := s.db.Unscoped().Model(model).Where(query, args...).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func TestForeignKeysAreEnforced(t *testing.T) {
+	s := newTestStore(t)
+
+	if err := s.db.Create(&Item{TaskId: 999, Title: "Orphan"}).Error; err == nil {
+		t.Fatal("an item of a task that doesn't exist was created")
+	}
+
+	if err := s.db.Create(&Member{ListId: 999, UserId: 999, Role: RoleViewer}).Error; err == nil {
+		t.Fatal("a member of a list that doesn't exist was created")
+	}
+}
+
+func TestForeignKeysCascade(t *testing.T) {
+	s := newTestStore(t)
+	owner := createTestUser(t, s, "Owner")
+	editor := createTestUser(t, s, "Editor")
+
+	list := createTestList(t, s, owner.Id, "Groceries")
+	if _, err := s.CreateMember(Member{ListId: list.Id, UserId: editor.Id, Role: RoleEditor}); err != nil {
+		t.Fatal(err)
+	}
+
+	task := createTestTask(t, s, list.Id, owner.Id, "Buy milk")
+	other := createTestTask(t, s, list.Id, editor.Id, "Buy bread")
+	if _, err := s.CreateItem(Item{TaskId: task.Id, Title: "Oat milk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := s.CreateTag(Tag{OwnerId: owner.Id, Name: "urgent", Color: "#ef4444"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTaskTags(task.Id, owner.Id, []Tag{tag}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTaskTags(other.Id, owner.Id, []Tag{tag}); err != nil {
+		t.Fatal(err)
+	}
+
+	// purging a task deletes its items and its links to the tags, but not the tags
+	if err := s.PurgeTask(task.Id); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, s, &Item{}, "task_id = ?", task.Id); n != 0 {
+		t.Errorf("%d items of the purged task are left", n)
+	}
+	if n := countRows(t, s, &TaskTag{}, "task_id = ?", task.Id); n != 0 {
+		t.Errorf("%d tags are linked to the purged task", n)
+	}
+	if n := countRows(t, s, &Tag{}, "id = ?", tag.Id); n != 1 {
+		t.Errorf("the tag of the purged task was deleted")
+	}
+
+	// deleting a tag removes it from the tasks
+	if err := s.DeleteTag(tag.Id); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, s, &TaskTag{}, "tag_id = ?", tag.Id); n != 0 {
+		t.Errorf("the deleted tag is still linked to %d tasks", n)
+	}
+
+	// the tasks are kept without their creator
+	if err := s.db.Delete(&User{}, editor.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	kept, ok := s.TaskExists(other.Id)
+	if !ok || kept.CreatedById != nil {
+		t.Errorf("the task of the deleted user = %+v, %v, want it without a creator", kept, ok)
+	}
+	if n := countRows(t, s, &Member{}, "user_id = ?", editor.Id); n != 0 {
+		t.Errorf("%d memberships of the deleted user are left", n)
+	}
+
+	// purging a list deletes its tasks and members
+	if err := s.PurgeList(list.Id); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, s, &Task{}, "list_id = ?", list.Id); n != 0 {
+		t.Errorf("%d tasks of the purged list are left", n)
+	}
+}
+
+func TestDeletingUserDeletesTheirData(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Leaving User")
+
+	list := createTestList(t, s, user.Id, "Private")
+	createTestTask(t, s, list.Id, user.Id, "Secret")
+
+	if _, err := s.CreateTag(Tag{OwnerId: user.Id, Name: "home", Color: "#ffffff"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.CreateSession(user.Id, "test", "127.0.0.1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateToken(user.Id, TokenReset, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateAccessToken(user.Id, NewAccessToken{Name: "script", Scopes: []string{ScopeRead}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.db.Delete(&User{}, user.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rows := range []struct {
+		Model interface{}
+		Query string
+	}{
+		{&List{}, "owner_id = ?"},
+		{&Tag{}, "owner_id = ?"},
+		{&Session{}, "user_id = ?"},
+		{&Token{}, "user_id = ?"},
+		{&AccessToken{}, "user_id = ?"},
+	} {
+		if n := countRows(t, s, rows.Model, rows.Query, user.Id); n != 0 {
+			t.Errorf("%d rows of %T are left", n, rows.Model)
+		}
+	}
+
+	if n := countRows(t, s, &Task{}, "list_id = ?", list.Id); n != 0 {
+		t.Errorf("%d tasks of the deleted list are left", n)
+	}
+}
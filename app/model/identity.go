@@ -0,0 +1,112 @@
+package model
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// defining a model struct
+// an identity links a user to an account of an OIDC provider
+type Identity struct {
+	Id       int    `gorm:"primaryKey"`
+	UserId   int    `gorm:"not null;index"`
+	Provider string `gorm:"not null;size:64;uniqueIndex:idx_identities_provider_subject"`
+	Subject  string `gorm:"not null;size:255;uniqueIndex:idx_identities_provider_subject"`
+}
+
+// returns the user of the provider account
+// on the first login the user with the same email is linked, or a new user is created without a password
+// the email has to be verified by the provider
+func (s *GormStore) LoginWithIdentity(provider string, subject string, email string, name string) (User, error) {
+	var user User
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// if the account has already been linked
+		var identity Identity
+		res := tx.Where("provider = ? AND subject = ?", provider, subject).Limit(1).Find(&identity)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return tx.Where("id = ?", identity.UserId).First(&user).Error
+		}
+
+		// linking the user with the same email
+		res = tx.Where("email = ?", email).Limit(1).Find(&user)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected > 0 {
+			// the provider has verified the email, so the user doesn't have to
+			if !user.IsEnabled {
+				if err := claimUnverifiedUser(tx, &user, identityName(name, email)); err != nil {
+					return err
+				}
+			}
+		} else {
+			user = User{
+				Name:      identityName(name, email),
+				Email:     email,
+				IsEnabled: true,
+			}
+
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		}
+
+		identity = Identity{UserId: user.Id, Provider: provider, Subject: subject}
+		return tx.Create(&identity).Error
+	})
+
+	return user, err
+}
+
+// enables a user whose email has never been verified, for the owner of the email
+// anyone could have registered with the email before, so the password and everything else
+// they could have set up to get into the account later is removed
+func claimUnverifiedUser(tx *gorm.DB, user *User, name string) error {
+	err := tx.Model(&User{}).Where("id = ?", user.Id).Updates(map[string]interface{}{
+		"is_enabled":     true,
+		"name":           name,
+		"password":       "",
+		"avatar_url":     "",
+		"pending_email":  "",
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	// the pending verification, reset and 2fa tokens, the sessions and the access tokens
+	for _, model := range []interface{}{&Token{}, &RecoveryCode{}, &AccessToken{}} {
+		if err := tx.Where("user_id = ?", user.Id).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := revokeSessions(tx, user.Id, 0); err != nil {
+		return err
+	}
+
+	return tx.Where("id = ?", user.Id).First(user).Error
+}
+
+// returns the name of a new user from the claims of the provider
+func identityName(name string, email string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	// the name is cut by characters, so a multi-byte character isn't split
+	if runes := []rune(name); len(runes) > 64 {
+		name = string(runes[:64])
+	}
+
+	return name
+}
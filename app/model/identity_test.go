@@ -0,0 +1,32 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestIdentityName(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"John Doe", "john@example.com", "John Doe"},
+		{"  John Doe ", "john@example.com", "John Doe"},
+		{"", "john.doe@example.com", "john.doe"},
+		{strings.Repeat("a", 70), "john@example.com", strings.Repeat("a", 64)},
+		{strings.Repeat("á", 70), "john@example.com", strings.Repeat("á", 64)},
+		{strings.Repeat("a", 63) + "日本", "john@example.com", strings.Repeat("a", 63) + "日"},
+	}
+
+	for _, test := range tests {
+		got := identityName(test.name, test.email)
+		if got != test.want {
+			t.Errorf("identityName(%q, %q) = %q, want %q", test.name, test.email, got, test.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("identityName(%q, %q) = %q is not valid utf-8", test.name, test.email, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package model
+
+import "gorm.io/gorm"
+
+// a checklist item of a task
+type Item struct {
+	Id       int    `json:"id" gorm:"primaryKey" example:"1"`
+	TaskId   int    `json:"taskId" gorm:"not null;column:task_id;index" example:"1"`
+	Title    string `json:"title" gorm:"not null" example:"Buy milk"`
+	IsDone   bool   `json:"isDone" gorm:"not null;column:is_done" example:"false"`
+	Position int    `json:"position" gorm:"not null" example:"1"`
+
+	Task *Task `json:"-" gorm:"foreignKey:TaskId;constraint:OnDelete:CASCADE"`
+}
+
+// the number of done and all checklist items of a task
+type Progress struct {
+	Done  int `json:"done" example:"2"`
+	Total int `json:"total" example:"5"`
+}
+
+func (item Item) Validate() (bool, string) {
+	// if the title is empty
+	if len(item.Title) < 1 {
+		return false, "The title can not be empty."
+	}
+
+	// if the title is too long
+	if len(item.Title) > 64 {
+		return false, "The title can be maximum 64 characters long."
+	}
+
+	return true, ""
+}
+
+func (s *GormStore) GetItems(taskId int) ([]Item, error) {
+	var items []Item
+
+	// getting the items of the task from the db
+	// the result-set should be ordered in ascending order by position
+	tx := s.db.Where("task_id = ?", taskId).Order("position ASC, id ASC").Find(&items)
+	if tx.Error != nil {
+		return []Item{}, tx.Error
+	}
+
+	return items, nil
+}
+
+func (s *GormStore) GetItemById(id int) (Item, error) {
+	// getting the item from the db by id
+	var item Item
+	tx := s.db.Where("id = ?", id).First(&item)
+	return item, tx.Error
+}
+
+func (s *GormStore) CreateItem(item Item) (Item, error) {
+	// the new item goes to the end of the checklist
+	var last int
+	s.db.Model(&Item{}).Select("COALESCE(MAX(position), 0)").Where("task_id = ?", item.TaskId).Scan(&last)
+	item.Position = last + 1
+
+	// creating the item in the db
+	tx := s.db.Create(&item)
+	return item, tx.Error
+}
+
+func (s *GormStore) EditItem(item Item) (Item, error) {
+	// saving the item in the db
+	tx := s.db.Save(&item)
+	return item, tx.Error
+}
+
+func (s *GormStore) DeleteItem(id int) error {
+	// deleting the item from the db
+	tx := s.db.Unscoped().Delete(&Item{}, id)
+	return tx.Error
+}
+
+func copyItems(tx *gorm.DB, fromTaskId int, toTaskId int) error {
+	var items []Item
+
+	// getting the items of the original task
+	if err := tx.Where("task_id = ?", fromTaskId).Find(&items).Error; err != nil {
+		return err
+	}
+
+	// nothing to copy
+	if len(items) == 0 {
+		return nil
+	}
+
+	// creating the copies as not done items
+	for i := range items {
+		items[i].Id = 0
+		items[i].TaskId = toTaskId
+		items[i].IsDone = false
+	}
+
+	return tx.Create(&items).Error
+}
+
+func loadProgress(db *gorm.DB, tasks []Task) error {
+	// nothing to count
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	// collecting the ids of the tasks
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.Id
+	}
+
+	// counting the done and all items of every task
+	var counts []struct {
+		TaskId int
+		Done   int
+		Total  int
+	}
+	tx := db.Model(&Item{}).
+		Select("task_id, SUM(CASE WHEN is_done THEN 1 ELSE 0 END) AS done, COUNT(*) AS total").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&counts)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	// setting the progress of the tasks
+	progress := map[int]Progress{}
+	for _, count := range counts {
+		progress[count.TaskId] = Progress{Done: count.Done, Total: count.Total}
+	}
+	for i := range tasks {
+		tasks[i].Progress = progress[tasks[i].Id]
+	}
+
+	return nil
+}
@@ -2,16 +2,25 @@
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/0l1v3rr/todo/app/util"
+	"gorm.io/gorm"
 )
 
 type List struct {
-	Id       int    `json:"id" gorm:"primaryKey" example:"1"`
-	OwnerId  int    `json:"ownerId" gorm:"not null;column:owner_id" example:"1"`
-	ImageUrl string `json:"imageURL" gorm:"column:image_url" example:"/assets/images/hfhu39Hfeu.png"`
-	Name     string `json:"name" gorm:"not null" example:"List"`
-	Url      string `json:"url" gorm:"unique" example:"list-1"`
+	Id       int     `json:"id" gorm:"primaryKey" example:"1"`
+	OwnerId  int     `json:"ownerId" gorm:"not null;column:owner_id" example:"1"`
+	ImageUrl string  `json:"imageURL" gorm:"column:image_url" example:"/assets/images/hfhu39Hfeu.png"`
+	Name     string  `json:"name" gorm:"not null" example:"List"`
+	Url      string  `json:"url" gorm:"unique" example:"list-1"`
+	Position float64 `json:"position" gorm:"not null;default:0" example:"1024"`
+
+	// the deleted lists are kept in the trash of their owner until they are restored or purged
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Owner *User `json:"-" gorm:"foreignKey:OwnerId;constraint:OnDelete:CASCADE"`
 }
 
 func (list List) Validate() (bool, string) {
@@ -28,24 +37,110 @@ func (list List) Validate() (bool, string) {
 	return true, ""
 }
 
-func GetLists(ownerId int) ([]List, error) {
+// the keys of the orders the sidebar of a user can be sorted by
+var (
+	listIdKey       = sortKey{Column: "lists.id", Desc: true, Kind: kindInt}
+	listNameKey     = sortKey{Column: "lists.name", Kind: kindText}
+	listPositionKey = sortKey{Column: listPosition, Kind: kindFloat}
+)
+
+var listSorts = map[string][]sortKey{
+	"position": {listPositionKey, listIdKey},
+	"name":     {listNameKey, listIdKey},
+	"created":  {listIdKey},
+}
+
+// the filters of the lists of a user
+type ListFilter struct {
+	Name string
+}
+
+func IsValidListSort(sort string) bool {
+	_, ok := listSorts[sort]
+	return ok
+}
+
+// returns the values of the sort keys of the list
+func (list List) sortValues(keys []sortKey) []interface{} {
+	values := make([]interface{}, len(keys))
+
+	for i, key := range keys {
+		switch key {
+		case listIdKey:
+			values[i] = list.Id
+		case listNameKey:
+			values[i] = list.Name
+		case listPositionKey:
+			values[i] = list.Position
+		}
+	}
+
+	return values
+}
+
+func (s *GormStore) GetLists(userId int, filter ListFilter, sort string, page Page) ([]List, PageInfo, error) {
 	var lists []List
 
-	// getting the lists from the db where
-	// the result-set should be ordered in descending order by id
-	tx := DB.Where("owner_id = ?", ownerId).Order("id DESC").Find(&lists)
+	// falling back to the default order if the sort is unknown
+	keys, ok := listSorts[sort]
+	if !ok {
+		keys = listSorts["position"]
+	}
+
+	// getting the owned and the shared lists from the db
+	tx := sidebar(s.db, userId).Select("lists.id, lists.owner_id, lists.image_url, lists.name, lists.url, " + listPosition + " AS position")
+
+	// applying the filters
+	if filter.Name != "" {
+		tx = tx.Where("LOWER(lists.name) LIKE ?", "%"+strings.ToLower(filter.Name)+"%")
+	}
+
+	// the result-set should be ordered by the specified sort and
+	// should only contain the rows of the requested page
+	tx, backward, err := paginate(tx, keys, page)
+	if err != nil {
+		return []List{}, PageInfo{}, err
+	}
+
+	tx = tx.Find(&lists)
 	if tx.Error != nil {
-		return []List{}, tx.Error
+		return []List{}, PageInfo{}, tx.Error
+	}
+
+	// removing the extra row and restoring the order of a previous page
+	hasMore := page.Limit > 0 && len(lists) > page.Limit
+	if hasMore {
+		lists = lists[:page.Limit]
 	}
+	if backward {
+		for i, j := 0, len(lists)-1; i < j; i, j = i+1, j-1 {
+			lists[i], lists[j] = lists[j], lists[i]
+		}
+	}
+
+	// creating the cursors of the next and the previous pages
+	var first, last []interface{}
+	if len(lists) > 0 {
+		first, last = lists[0].sortValues(keys), lists[len(lists)-1].sortValues(keys)
+	}
+
+	return lists, pageInfo(page, backward, hasMore, first, last), nil
+}
+
+// returns a subquery selecting the ids of the lists
+// the user owns or is a member of
+func accessibleListIds(db *gorm.DB, userId int) *gorm.DB {
+	// the ids of the lists shared with the user
+	shared := db.Model(&Member{}).Select("list_id").Where("user_id = ?", userId)
 
-	return lists, nil
+	return db.Model(&List{}).Select("id").Where("owner_id = ?", userId).Or("id IN (?)", shared)
 }
 
-func GetListByUrl(url string) (List, error) {
+func (s *GormStore) GetListByUrl(url string) (List, error) {
 	var list List
 
 	// getting the list from the db by the specified url
-	tx := DB.Where("url = ?", url).First(&list)
+	tx := s.db.Where("url = ?", url).First(&list)
 	if tx.Error != nil {
 		return List{}, tx.Error
 	}
@@ -53,23 +148,17 @@ func GetListByUrl(url string) (List, error) {
 	return list, nil
 }
 
-func GetListOwnerId(listId int) int {
+func (s *GormStore) GetListOwnerId(listId int) (int, error) {
 	// getting the list from the db
 	var list List
-	tx := DB.Where("id = ?", listId).First(&list)
-
-	// if there is an error, the list doesn't exist, so we return -1
-	if tx.Error != nil {
-		return -1
-	}
-
-	return list.OwnerId
+	tx := s.db.Select("owner_id").Where("id = ?", listId).First(&list)
+	return list.OwnerId, tx.Error
 }
 
-func ListExists(id int) (List, bool) {
+func (s *GormStore) ListExists(id int) (List, bool) {
 	// getting the list from the db
 	var list List
-	tx := DB.Where("id = ?", id).First(&list)
+	tx := s.db.Where("id = ?", id).First(&list)
 
 	// if there is an error, the list doesn't exist
 	if tx.Error != nil {
@@ -85,24 +174,37 @@ func ListExists(id int) (List, bool) {
 	return list, true
 }
 
-func CreateList(list List) (List, error) {
+func (s *GormStore) CreateList(list List) (List, error) {
 	// overriding the url
 	list.Url = fmt.Sprintf("%s-%s", util.CreateUrlByTitle(list.Name), util.GenerateHash(8))
 	list.ImageUrl = ""
 
+	// the new list goes to the top of the sidebar
+	list.Position = firstListPosition(s.db, list.OwnerId)
+
 	// creating the list in the db
-	tx := DB.Create(&list)
+	tx := s.db.Create(&list)
 	return list, tx.Error
 }
 
-func EditList(list List) (List, error) {
+func (s *GormStore) EditList(list List) (List, error) {
 	// saving the edited list in the db
-	tx := DB.Save(&list)
+	tx := s.db.Save(&list)
 	return list, tx.Error
 }
 
-func DeleteList(id int) error {
-	// deleting the list from the db
-	tx := DB.Unscoped().Delete(&List{}, id)
-	return tx.Error
+func (s *GormStore) DeleteList(id int) error {
+	// moving the list to the trash together with its tasks,
+	// which are marked so restoring the list restores them too
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		err := tx.Model(&Task{}).Where("list_id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": now, "deleted_with_list": true}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&List{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
 }
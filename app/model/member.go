@@ -0,0 +1,128 @@
+package model
+
+// the roles a user can have in a list
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// the rank of each role, a higher rank includes
+// every permission of the lower ones
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// a user the list is shared with
+type Member struct {
+	Id       int     `json:"id" gorm:"primaryKey" example:"1"`
+	ListId   int     `json:"listId" gorm:"not null;column:list_id;uniqueIndex:idx_member_list_user" example:"1"`
+	UserId   int     `json:"userId" gorm:"not null;column:user_id;uniqueIndex:idx_member_list_user" example:"2"`
+	Role     string  `json:"role" gorm:"not null" example:"editor"`
+	Position float64 `json:"-" gorm:"not null;default:0"`
+	Name     string  `json:"name,omitempty" gorm:"->;-:migration" example:"John Doe"`
+	Email    string  `json:"email,omitempty" gorm:"->;-:migration" example:"johndoe@gmail.com"`
+
+	// the membership is deleted with the list or the user
+	List *List `json:"-" gorm:"foreignKey:ListId;constraint:OnDelete:CASCADE"`
+	User *User `json:"-" gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE"`
+}
+
+// defining an InviteMember for the documentation
+type InviteMember struct {
+	ListId int    `json:"listId" example:"1"`
+	Email  string `json:"email" example:"johndoe@gmail.com"`
+	Role   string `json:"role" example:"editor"`
+}
+
+// the owner role only belongs to the owner of the list, so it can't be given to a member
+// a member could delete the list otherwise, but only the owner can restore it from the trash
+func IsMemberRole(role string) bool {
+	return role == RoleViewer || role == RoleEditor
+}
+
+func (member Member) Validate() (bool, string) {
+	// if the role can't be given to a member
+	if !IsMemberRole(member.Role) {
+		return false, "The role has to be viewer or editor."
+	}
+
+	return true, ""
+}
+
+func (s *GormStore) GetRole(listId int, userId int) string {
+	// the owner of the list always has the owner role
+	if ownerId, err := s.GetListOwnerId(listId); err == nil && ownerId == userId {
+		return RoleOwner
+	}
+
+	// getting the membership from the db
+	var member Member
+	tx := s.db.Where("list_id = ? AND user_id = ?", listId, userId).First(&member)
+
+	// if there is an error, the user is not a member of the list
+	if tx.Error != nil {
+		return ""
+	}
+
+	return member.Role
+}
+
+func (s *GormStore) HasRole(listId int, userId int, role string) bool {
+	// the user has the role if its rank is at least the required one
+	return roleRanks[s.GetRole(listId, userId)] >= roleRanks[role]
+}
+
+func (s *GormStore) GetMembers(listId int) ([]Member, error) {
+	var members []Member
+
+	// getting the members of the list together with their names and emails
+	tx := s.db.Table("members").
+		Select("members.*, users.name, users.email").
+		Joins("JOIN users ON users.id = members.user_id").
+		Where("members.list_id = ?", listId).
+		Order("members.id").
+		Find(&members)
+	if tx.Error != nil {
+		return []Member{}, tx.Error
+	}
+
+	return members, nil
+}
+
+func (s *GormStore) GetMemberById(id int) (Member, error) {
+	// getting the member from the db by id
+	var member Member
+	tx := s.db.Where("id = ?", id).First(&member)
+	return member, tx.Error
+}
+
+func (s *GormStore) IsMember(listId int, userId int) bool {
+	// counting the memberships of the user in the list
+	var count int64
+	s.db.Model(&Member{}).Where("list_id = ? AND user_id = ?", listId, userId).Count(&count)
+	return count > 0
+}
+
+func (s *GormStore) CreateMember(member Member) (Member, error) {
+	// the shared list goes to the top of the sidebar of the member
+	member.Position = firstListPosition(s.db, member.UserId)
+
+	// creating the member in the db
+	tx := s.db.Create(&member)
+	return member, tx.Error
+}
+
+func (s *GormStore) EditMember(member Member) (Member, error) {
+	// saving the member in the db
+	tx := s.db.Save(&member)
+	return member, tx.Error
+}
+
+func (s *GormStore) DeleteMember(id int) error {
+	// deleting the member from the db
+	tx := s.db.Unscoped().Delete(&Member{}, id)
+	return tx.Error
+}
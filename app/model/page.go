@@ -0,0 +1,220 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the kinds of the values of the sort keys
+const (
+	kindInt   = "int"
+	kindFloat = "float"
+	kindTime  = "time"
+	kindText  = "text"
+)
+
+// a column (or an expression) of the order of a collection,
+// the last key has to be unique, so every row has a different position
+type sortKey struct {
+	Column   string
+	Desc     bool
+	Kind     string
+	Nullable bool
+}
+
+// the requested page of a collection, a zero limit means every row
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+// the cursors of the next and the previous pages,
+// an empty cursor means that there is no such page
+type PageInfo struct {
+	Next string
+	Prev string
+}
+
+// the decoded content of a cursor
+type cursor struct {
+	Values   []interface{} `json:"v"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+var ErrInvalidCursor = errors.New("the cursor is not valid")
+
+// returns the ORDER BY clause of the keys
+func orderBy(keys []sortKey) string {
+	return orderByDirection(keys, false)
+}
+
+func orderByDirection(keys []sortKey, reverse bool) string {
+	parts := make([]string, len(keys))
+
+	for i, key := range keys {
+		direction := "ASC"
+		if key.Desc != reverse {
+			direction = "DESC"
+		}
+
+		parts[i] = key.Column + " " + direction
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// adds the order, the limit and the conditions of the cursor to the query,
+// it returns whether the rows have to be reversed after the query
+func paginate(tx *gorm.DB, keys []sortKey, page Page) (*gorm.DB, bool, error) {
+	c, err := decodeCursor(page.Cursor, keys)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// the rows after (or before) the cursor in the order
+	if c != nil {
+		tx = tx.Where(afterCursor(tx.Session(&gorm.Session{NewDB: true}), keys, c.Values, c.Backward))
+	}
+
+	// the previous page is queried in the reversed order
+	backward := c != nil && c.Backward
+	tx = tx.Order(orderByDirection(keys, backward))
+
+	// querying one more row to know whether there are more pages
+	if page.Limit > 0 {
+		tx = tx.Limit(page.Limit + 1)
+	}
+
+	return tx, backward, nil
+}
+
+// builds the keyset condition that selects the rows after the values in the order,
+// or before them if backward is set, the conditions are grouped on db, which has no other conditions
+func afterCursor(db *gorm.DB, keys []sortKey, values []interface{}, backward bool) *gorm.DB {
+	condition := db.Where("1 = 0")
+
+	for i, key := range keys {
+		// nothing comes after a null inside the same key
+		if values[i] == nil {
+			continue
+		}
+
+		operator := ">"
+		if key.Desc != backward {
+			operator = "<"
+		}
+
+		// the previous keys are equal and this one is after the value
+		part := db.Where(key.Column+" "+operator+" ?", values[i])
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				part = part.Where(keys[j].Column + " IS NULL")
+			} else {
+				part = part.Where(keys[j].Column+" = ?", values[j])
+			}
+		}
+
+		condition = condition.Or(part)
+	}
+
+	return condition
+}
+
+func encodeCursor(values []interface{}, backward bool) string {
+	data, _ := json.Marshal(cursor{Values: values, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string, keys []sortKey) (*cursor, error) {
+	// no cursor means the first page
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var raw struct {
+		Values   []json.RawMessage `json:"v"`
+		Backward bool              `json:"b"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	// decoding the values into the types of the keys
+	c := cursor{Values: make([]interface{}, len(keys)), Backward: raw.Backward}
+	for i, key := range keys {
+		if string(raw.Values[i]) == "null" {
+			if !key.Nullable {
+				return nil, ErrInvalidCursor
+			}
+			continue
+		}
+
+		var err error
+		switch key.Kind {
+		case kindInt:
+			var v int
+			err = json.Unmarshal(raw.Values[i], &v)
+			c.Values[i] = v
+		case kindFloat:
+			var v float64
+			err = json.Unmarshal(raw.Values[i], &v)
+			c.Values[i] = v
+		case kindTime:
+			var v time.Time
+			err = json.Unmarshal(raw.Values[i], &v)
+			c.Values[i] = v
+		default:
+			var v string
+			err = json.Unmarshal(raw.Values[i], &v)
+			c.Values[i] = v
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &c, nil
+}
+
+// returns the cursors of the pages around the queried rows,
+// first and last are the sort values of the first and last rows
+func pageInfo(page Page, backward bool, hasMore bool, first []interface{}, last []interface{}) PageInfo {
+	var info PageInfo
+
+	// every row has been returned
+	if page.Limit == 0 || first == nil {
+		return info
+	}
+
+	// there is a next page if there were more rows forward,
+	// or if we came backward from a page after these rows
+	if (!backward && hasMore) || backward {
+		info.Next = encodeCursor(last, false)
+	}
+
+	// there is a previous page if there were more rows backward,
+	// or if we came forward from a page before these rows
+	if (backward && hasMore) || (!backward && page.Cursor != "") {
+		info.Prev = encodeCursor(first, true)
+	}
+
+	return info
+}
+
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return *t
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// walks the pages of the tasks forward and then backward, and returns the ids of every page
+func taskPages(t *testing.T, s *GormStore, listId int, userId int, sort string, limit int) [][]int {
+	t.Helper()
+
+	var pages [][]int
+	var infos []PageInfo
+
+	cursor := ""
+	for {
+		tasks, info, err := s.GetTasks(listId, userId, TaskFilter{}, sort, Page{Limit: limit, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pages = append(pages, taskIds(tasks))
+		infos = append(infos, info)
+
+		if info.Next == "" {
+			break
+		}
+		if len(pages) > 10 {
+			t.Fatal("the pages never end")
+		}
+		cursor = info.Next
+	}
+
+	if infos[0].Prev != "" {
+		t.Errorf("the first page has a previous page")
+	}
+
+	// going back from the last page gives the same pages
+	for i := len(pages) - 1; i > 0; i-- {
+		tasks, info, err := s.GetTasks(listId, userId, TaskFilter{}, sort, Page{Limit: limit, Cursor: infos[i].Prev})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ids := taskIds(tasks); !reflect.DeepEqual(ids, pages[i-1]) {
+			t.Errorf("page %d backward = %v, want %v", i-1, ids, pages[i-1])
+		}
+		if (info.Prev == "") != (i-1 == 0) {
+			t.Errorf("page %d backward has prev = %q", i-1, info.Prev)
+		}
+	}
+
+	return pages
+}
+
+func TestTaskPagination(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Page User")
+	list := createTestList(t, s, user.Id, "Paged")
+
+	// the tasks are created with the same time, so the id decides their order
+	created := time.Date(2022, 7, 20, 8, 0, 0, 0, time.UTC)
+	due := func(day int) *time.Time {
+		d := time.Date(2022, 8, day, 12, 0, 0, 0, time.UTC)
+		return &d
+	}
+
+	var all []Task
+	for i, date := range []*time.Time{due(3), nil, due(1), nil, due(3), due(2), nil} {
+		task := createTestTask(t, s, list.Id, user.Id, "Task")
+		task.CreatedAt = created
+		task.DueDate = date
+		task.Priority = i % 3
+		if _, err := s.EditTask(task); err != nil {
+			t.Fatal(err)
+		}
+
+		all = append(all, task)
+	}
+
+	for _, sort := range []string{"created", "due", "priority", "position"} {
+		t.Run(sort, func(t *testing.T) {
+			// every row in one page
+			tasks, info, err := s.GetTasks(list.Id, user.Id, TaskFilter{}, sort, Page{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Next != "" || info.Prev != "" {
+				t.Errorf("the unpaged tasks have other pages: %+v", info)
+			}
+			want := taskIds(tasks)
+
+			// the pages contain the same rows in the same order
+			got := []int{}
+			for _, page := range taskPages(t, s, list.Id, user.Id, sort, 3) {
+				if len(page) > 3 {
+					t.Errorf("the page has %d tasks, want maximum 3", len(page))
+				}
+				got = append(got, page...)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("pages = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// the tasks without a due date come last, the equal ones are ordered by id
+	tasks, _, err := s.GetTasks(list.Id, user.Id, TaskFilter{}, "due", Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{all[2].Id, all[5].Id, all[0].Id, all[4].Id, all[1].Id, all[3].Id, all[6].Id}
+	if ids := taskIds(tasks); !reflect.DeepEqual(ids, want) {
+		t.Errorf("tasks by due date = %v, want %v", ids, want)
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Page User")
+	list := createTestList(t, s, user.Id, "Paged")
+
+	_, _, err := s.GetTasks(list.Id, user.Id, TaskFilter{}, "created", Page{Limit: 2, Cursor: "not a cursor"})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("err = %v, want ErrInvalidCursor", err)
+	}
+
+	// a cursor of another order doesn't fit the keys
+	createTestTask(t, s, list.Id, user.Id, "One")
+	createTestTask(t, s, list.Id, user.Id, "Two")
+	_, info, err := s.GetTasks(list.Id, user.Id, TaskFilter{}, "created", Page{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = s.GetTasks(list.Id, user.Id, TaskFilter{}, "due", Page{Limit: 1, Cursor: info.Next})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Page User")
+	friend := createTestUser(t, s, "Friend User")
+
+	for _, name := range []string{"Work", "Home", "Books", "Garden"} {
+		createTestList(t, s, user.Id, name)
+	}
+
+	// the shared lists are in the sidebar too
+	shared := createTestList(t, s, friend.Id, "Shared")
+	if _, err := s.CreateMember(Member{ListId: shared.Id, UserId: user.Id, Role: RoleViewer}); err != nil {
+		t.Fatal(err)
+	}
+	createTestList(t, s, friend.Id, "Not shared")
+
+	names := []string{}
+	cursor := ""
+	for {
+		lists, info, err := s.GetLists(user.Id, ListFilter{}, "name", Page{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, list := range lists {
+			names = append(names, list.Name)
+		}
+
+		if info.Next == "" {
+			break
+		}
+		cursor = info.Next
+	}
+
+	want := []string{"Books", "Garden", "Home", "Shared", "Work"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("lists = %v, want %v", names, want)
+	}
+}
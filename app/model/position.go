@@ -0,0 +1,189 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// the distance between the positions after a rebalance
+const positionGap = 1024.0
+
+// if two neighbours get closer than this, the positions are rebalanced
+const minPositionGap = 1e-6
+
+// defining a Reorder for the documentation,
+// only one of the ids should be specified
+type Reorder struct {
+	BeforeId int `json:"beforeId" example:"3"`
+	AfterId  int `json:"afterId" example:"0"`
+}
+
+// an item with a position in an ordered collection
+type positioned struct {
+	Id       int
+	Position float64
+}
+
+var ErrTargetNotFound = errors.New("the target is not in the same collection")
+
+func (s *GormStore) MoveTask(id int, targetId int, after bool) (Task, error) {
+	// getting the moved task
+	task, err := s.GetTaskById(id)
+	if err != nil {
+		return Task{}, err
+	}
+
+	// getting the ordered positions of the tasks in the list
+	var items []positioned
+	tx := s.db.Model(&Task{}).Select("id, position").Where("list_id = ?", task.ListId).Order(orderBy(taskSorts["position"])).Scan(&items)
+	if tx.Error != nil {
+		return Task{}, tx.Error
+	}
+
+	// calculating the new positions
+	positions, err := reposition(items, id, targetId, after)
+	if err != nil {
+		return Task{}, err
+	}
+
+	// saving the changed positions
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for itemId, position := range positions {
+			if err := tx.Model(&Task{}).Where("id = ?", itemId).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+
+	task.Position = positions[id]
+	return task, nil
+}
+
+func (s *GormStore) MoveList(userId int, id int, targetId int, after bool) error {
+	// getting the ordered positions of the lists in the sidebar of the user
+	var items []positioned
+	tx := sidebar(s.db, userId).
+		Select("lists.id, " + listPosition + " AS position").
+		Order(orderBy(listSorts["position"])).
+		Scan(&items)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	// calculating the new positions
+	positions, err := reposition(items, id, targetId, after)
+	if err != nil {
+		return err
+	}
+
+	// saving the changed positions, the position of an owned list is stored in the list
+	// and the position of a shared list is stored in the membership of the user
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for listId, position := range positions {
+			update := tx.Model(&List{}).Where("id = ? AND owner_id = ?", listId, userId).Update("position", position)
+			if update.Error != nil {
+				return update.Error
+			}
+
+			if update.RowsAffected > 0 {
+				continue
+			}
+
+			err := tx.Model(&Member{}).Where("list_id = ? AND user_id = ?", listId, userId).Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// the position of a list in the sidebar query, shared lists use the position of the membership
+const listPosition = "CASE WHEN members.user_id IS NULL THEN lists.position ELSE members.position END"
+
+// returns a query of the lists the user owns or is a member of
+func sidebar(db *gorm.DB, userId int) *gorm.DB {
+	return db.Model(&List{}).
+		Joins("LEFT JOIN members ON members.list_id = lists.id AND members.user_id = ?", userId).
+		Where("lists.owner_id = ? OR members.user_id = ?", userId, userId)
+}
+
+// returns the position before the first item of the sidebar of the user
+func firstListPosition(db *gorm.DB, userId int) float64 {
+	var first positioned
+	sidebar(db, userId).
+		Select("lists.id, " + listPosition + " AS position").
+		Order(orderBy(listSorts["position"])).
+		Limit(1).
+		Scan(&first)
+	return first.Position - positionGap
+}
+
+// returns the position before the first task of the list
+func firstTaskPosition(db *gorm.DB, listId int) float64 {
+	var first float64
+	db.Model(&Task{}).Select("COALESCE(MIN(position), 0)").Where("list_id = ?", listId).Scan(&first)
+	return first - positionGap
+}
+
+// returns the new positions of the changed items after moving
+// the item before or after the target, only the moved item changes
+// unless the neighbours are too close and every item has to be rebalanced
+func reposition(items []positioned, id int, targetId int, after bool) (map[int]float64, error) {
+	// removing the moved item from the order
+	rest := []positioned{}
+	for _, item := range items {
+		if item.Id != id {
+			rest = append(rest, item)
+		}
+	}
+
+	// the moved item has to be in the collection
+	if len(rest) == len(items) {
+		return nil, ErrTargetNotFound
+	}
+
+	// finding the index the item should be inserted at
+	index := -1
+	for i, item := range rest {
+		if item.Id == targetId {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, ErrTargetNotFound
+	}
+	if after {
+		index++
+	}
+
+	// placing the item between its new neighbours
+	switch {
+	case index == 0:
+		return map[int]float64{id: rest[0].Position - positionGap}, nil
+	case index == len(rest):
+		return map[int]float64{id: rest[index-1].Position + positionGap}, nil
+	}
+
+	prev, next := rest[index-1].Position, rest[index].Position
+	if next-prev >= minPositionGap {
+		return map[int]float64{id: prev + (next-prev)/2}, nil
+	}
+
+	// the neighbours are too close, so every item gets a new, evenly spaced position
+	ordered := append(append(append([]positioned{}, rest[:index]...), positioned{Id: id}), rest[index:]...)
+	positions := map[int]float64{}
+	for i, item := range ordered {
+		positions[item.Id] = float64(i+1) * positionGap
+	}
+
+	return positions, nil
+}
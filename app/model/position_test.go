@@ -0,0 +1,136 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func positionOrder(t *testing.T, s *GormStore, listId int, userId int) []int {
+	t.Helper()
+
+	tasks, _, err := s.GetTasks(listId, userId, TaskFilter{}, "position", Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return taskIds(tasks)
+}
+
+func TestMoveTask(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Position User")
+	list := createTestList(t, s, user.Id, "Ordered")
+	other := createTestList(t, s, user.Id, "Other")
+
+	// the new tasks go to the top
+	a := createTestTask(t, s, list.Id, user.Id, "A")
+	b := createTestTask(t, s, list.Id, user.Id, "B")
+	c := createTestTask(t, s, list.Id, user.Id, "C")
+	elsewhere := createTestTask(t, s, other.Id, user.Id, "Elsewhere")
+
+	if order := positionOrder(t, s, list.Id, user.Id); !reflect.DeepEqual(order, []int{c.Id, b.Id, a.Id}) {
+		t.Fatalf("order = %v, want the newest first", order)
+	}
+
+	moves := []struct {
+		id     int
+		target int
+		after  bool
+		want   []int
+	}{
+		{a.Id, c.Id, false, []int{a.Id, c.Id, b.Id}},
+		{a.Id, b.Id, true, []int{c.Id, b.Id, a.Id}},
+		{c.Id, b.Id, true, []int{b.Id, c.Id, a.Id}},
+		{b.Id, a.Id, false, []int{c.Id, b.Id, a.Id}},
+	}
+
+	for _, move := range moves {
+		if _, err := s.MoveTask(move.id, move.target, move.after); err != nil {
+			t.Fatal(err)
+		}
+
+		if order := positionOrder(t, s, list.Id, user.Id); !reflect.DeepEqual(order, move.want) {
+			t.Errorf("after moving %d next to %d: order = %v, want %v", move.id, move.target, order, move.want)
+		}
+	}
+
+	// the target has to be in the same list
+	if _, err := s.MoveTask(a.Id, elsewhere.Id, false); !errors.Is(err, ErrTargetNotFound) {
+		t.Errorf("err = %v, want ErrTargetNotFound", err)
+	}
+}
+
+func TestMoveTaskRebalances(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Position User")
+	list := createTestList(t, s, user.Id, "Crowded")
+
+	a := createTestTask(t, s, list.Id, user.Id, "A")
+	b := createTestTask(t, s, list.Id, user.Id, "B")
+	c := createTestTask(t, s, list.Id, user.Id, "C")
+
+	// there's no room between b and a
+	s.db.Model(&Task{}).Where("id = ?", b.Id).Update("position", 1.0)
+	s.db.Model(&Task{}).Where("id = ?", a.Id).Update("position", 1.0+minPositionGap/2)
+
+	if _, err := s.MoveTask(c.Id, b.Id, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if order := positionOrder(t, s, list.Id, user.Id); !reflect.DeepEqual(order, []int{b.Id, c.Id, a.Id}) {
+		t.Fatalf("order = %v, want %v", order, []int{b.Id, c.Id, a.Id})
+	}
+
+	// every task got an evenly spaced position
+	var positions []float64
+	s.db.Model(&Task{}).Where("list_id = ?", list.Id).Order("position ASC").Pluck("position", &positions)
+	if !reflect.DeepEqual(positions, []float64{positionGap, 2 * positionGap, 3 * positionGap}) {
+		t.Errorf("positions = %v, want them rebalanced", positions)
+	}
+}
+
+func TestMoveSharedList(t *testing.T) {
+	s := newTestStore(t)
+	owner := createTestUser(t, s, "List Owner")
+	member := createTestUser(t, s, "List Member")
+
+	shared := createTestList(t, s, owner.Id, "Shared")
+	ownerList := createTestList(t, s, owner.Id, "Owners")
+	if _, err := s.CreateMember(Member{ListId: shared.Id, UserId: member.Id, Role: RoleViewer}); err != nil {
+		t.Fatal(err)
+	}
+	memberList := createTestList(t, s, member.Id, "Members")
+
+	sidebar := func(userId int) []string {
+		lists, _, err := s.GetLists(userId, ListFilter{}, "position", Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := []string{}
+		for _, list := range lists {
+			names = append(names, list.Name)
+		}
+
+		return names
+	}
+
+	// the member puts the shared list to the top of their own sidebar
+	if err := s.MoveList(member.Id, shared.Id, memberList.Id, false); err != nil {
+		t.Fatal(err)
+	}
+	if names := sidebar(member.Id); !reflect.DeepEqual(names, []string{"Shared", "Members"}) {
+		t.Errorf("sidebar of the member = %v", names)
+	}
+
+	// the sidebar of the owner doesn't change
+	if names := sidebar(owner.Id); !reflect.DeepEqual(names, []string{"Owners", "Shared"}) {
+		t.Errorf("sidebar of the owner = %v", names)
+	}
+
+	// the lists of the other users can't be targets
+	if err := s.MoveList(member.Id, shared.Id, ownerList.Id, false); !errors.Is(err, ErrTargetNotFound) {
+		t.Errorf("err = %v, want ErrTargetNotFound", err)
+	}
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"sync"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/ratelimit"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// how long an unused key is kept in the db
+const rateLimitTTL = 24 * time.Hour
+
+// defining a model struct
+// it keeps the buckets and the lockouts of the rate limiter, so they are shared between the instances of the api
+type RateLimit struct {
+	Key           string     `gorm:"primaryKey;column:limit_key;size:191"`
+	Tokens        float64    `gorm:"not null;default:0"`
+	RefilledAt    time.Time  `gorm:"not null"`
+	Failures      int        `gorm:"not null;default:0"`
+	LastFailureAt *time.Time `gorm:"default:null"`
+	LockedUntil   *time.Time `gorm:"default:null"`
+}
+
+// the GormStore keeps the rate limits in the db
+var _ ratelimit.Store = (*GormStore)(nil)
+
+var (
+	sweepLock          sync.Mutex
+	lastRateLimitSweep time.Time
+)
+
+func (s *GormStore) Take(key string, limit ratelimit.Limit, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	now = now.UTC()
+
+	err := s.updateRateLimit(key, now, func(row *RateLimit, found bool) {
+		if !found {
+			row.Tokens = float64(limit.Burst)
+			row.RefilledAt = now
+		}
+
+		row.Tokens, wait = ratelimit.TakeToken(row.Tokens, row.RefilledAt, limit, now)
+		row.RefilledAt = now
+	})
+
+	s.sweepRateLimits(now)
+	return wait, err
+}
+
+func (s *GormStore) Fail(key string, lockout ratelimit.Lockout, now time.Time) (time.Duration, error) {
+	var locked time.Duration
+	now = now.UTC()
+
+	err := s.updateRateLimit(key, now, func(row *RateLimit, found bool) {
+		if !found {
+			row.RefilledAt = now
+		}
+
+		// the old failures are forgotten
+		if row.LastFailureAt == nil || now.Sub(*row.LastFailureAt) > lockout.Window {
+			row.Failures = 0
+		}
+
+		row.Failures++
+		row.LastFailureAt = &now
+
+		if duration := lockout.Duration(row.Failures); duration > 0 {
+			until := now.Add(duration)
+			row.LockedUntil = &until
+		}
+
+		if row.LockedUntil != nil && row.LockedUntil.After(now) {
+			locked = row.LockedUntil.Sub(now)
+		}
+	})
+
+	return locked, err
+}
+
+func (s *GormStore) LockedFor(key string, now time.Time) (time.Duration, error) {
+	var row RateLimit
+	tx := s.db.Where("limit_key = ?", key).Limit(1).Find(&row)
+	if tx.Error != nil || row.LockedUntil == nil || !row.LockedUntil.After(now) {
+		return 0, tx.Error
+	}
+
+	return row.LockedUntil.Sub(now), nil
+}
+
+func (s *GormStore) Reset(key string) error {
+	return s.db.Model(&RateLimit{}).Where("limit_key = ?", key).Updates(map[string]interface{}{
+		"failures":     0,
+		"locked_until": nil,
+	}).Error
+}
+
+// reads, changes and saves the row of the key in a transaction
+func (s *GormStore) updateRateLimit(key string, now time.Time, update func(row *RateLimit, found bool)) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// the row is locked, so the concurrent requests are counted correctly
+		// sqlite has no row locks, but it only has one writer anyway
+		query := tx.Where("limit_key = ?", key).Limit(1)
+		if tx.Dialector.Name() != "sqlite" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+
+		row := RateLimit{Key: key}
+		res := query.Find(&row)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		update(&row, res.RowsAffected > 0)
+		return tx.Save(&row).Error
+	})
+}
+
+// deletes the unused keys, at most once every 10 minutes
+func (s *GormStore) sweepRateLimits(now time.Time) {
+	sweepLock.Lock()
+	if now.Sub(lastRateLimitSweep) < 10*time.Minute {
+		sweepLock.Unlock()
+		return
+	}
+	lastRateLimitSweep = now
+	sweepLock.Unlock()
+
+	before := now.Add(-rateLimitTTL)
+	s.db.Where("refilled_at < ? AND (last_failure_at IS NULL OR last_failure_at < ?) AND (locked_until IS NULL OR locked_until < ?)", before, before, now).
+		Delete(&RateLimit{})
+}
@@ -0,0 +1,377 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/0l1v3rr/todo/app/util"
+	"gorm.io/gorm"
+)
+
+// the maximum number of search results
+const maxSearchResults = 50
+
+// a parsed search query, for example:
+//
+//	milk is:open list:home-x8fh3k due:2022-07-01..2022-07-31
+type SearchQuery struct {
+	Terms         []string
+	Done          *bool
+	ListUrl       string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+}
+
+// a task or a list matching the search query
+type SearchResult struct {
+	Type    string  `json:"type" example:"task"`
+	Id      int     `json:"id" example:"1"`
+	ListId  int     `json:"listId" example:"1"`
+	Url     string  `json:"url" example:"task-1"`
+	Title   string  `json:"title" example:"Buy milk"`
+	Snippet string  `json:"snippet" example:"Buy <mark>milk</mark> and bread"`
+	IsDone  bool    `json:"isDone" example:"false"`
+	Score   float64 `json:"score" example:"3"`
+}
+
+func ParseSearchQuery(q string, loc *time.Location) (SearchQuery, error) {
+	var query SearchQuery
+
+	for _, word := range strings.Fields(q) {
+		key, value, found := strings.Cut(word, ":")
+
+		// the words without a known filter are search terms
+		switch {
+		case found && key == "is" && value == "done":
+			done := true
+			query.Done = &done
+		case found && key == "is" && value == "open":
+			done := false
+			query.Done = &done
+		case found && key == "list" && value != "":
+			query.ListUrl = value
+		case found && key == "created":
+			from, to, err := parseDateRange(value, loc)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			query.CreatedAfter, query.CreatedBefore = from, to
+		case found && key == "due":
+			from, to, err := parseDateRange(value, loc)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			query.DueAfter, query.DueBefore = from, to
+		default:
+			// the terms only keep the letters and the digits
+			for _, term := range strings.FieldsFunc(word, isNotWordRune) {
+				query.Terms = append(query.Terms, strings.ToLower(term))
+			}
+		}
+	}
+
+	// there has to be something to search for
+	if len(query.Terms) == 0 && !query.hasFilters() {
+		return SearchQuery{}, errors.New("the query has no search terms or filters")
+	}
+
+	return query, nil
+}
+
+// parses a FROM..TO date range where both ends are optional
+// and the TO day is included in the range
+func parseDateRange(value string, loc *time.Location) (*time.Time, *time.Time, error) {
+	fromValue, toValue, found := strings.Cut(value, "..")
+	if !found {
+		// a single day
+		toValue = fromValue
+	}
+
+	var from, to *time.Time
+
+	if fromValue != "" {
+		t, err := time.ParseInLocation("2006-01-02", fromValue, loc)
+		if err != nil {
+			return nil, nil, errors.New("the dates have to be in YYYY-MM-DD format")
+		}
+		from = &t
+	}
+
+	if toValue != "" {
+		t, err := time.ParseInLocation("2006-01-02", toValue, loc)
+		if err != nil {
+			return nil, nil, errors.New("the dates have to be in YYYY-MM-DD format")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+
+	return from, to, nil
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func (query SearchQuery) hasFilters() bool {
+	return query.Done != nil || query.ListUrl != "" ||
+		query.CreatedAfter != nil || query.CreatedBefore != nil ||
+		query.DueAfter != nil || query.DueBefore != nil
+}
+
+func (s *GormStore) Search(userId int, query SearchQuery) ([]SearchResult, error) {
+	results := []SearchResult{}
+
+	// searching the tasks
+	tasks, err := s.searchTasks(userId, query)
+	if err != nil {
+		return []SearchResult{}, err
+	}
+
+	for _, task := range tasks {
+		// the snippet comes from the description if there is one
+		snippet := task.Description
+		if snippet == "" {
+			snippet = task.Title
+		}
+
+		results = append(results, SearchResult{
+			Type:    "task",
+			Id:      task.Id,
+			ListId:  task.ListId,
+			Url:     task.Url,
+			Title:   task.Title,
+			Snippet: util.Highlight(snippet, query.Terms, 60),
+			IsDone:  task.IsDone,
+			Score:   task.Score,
+		})
+	}
+
+	// the filters only apply to the tasks, so the lists are
+	// only searched if there are no filters
+	if len(query.Terms) > 0 && !query.hasFilters() {
+		lists, err := s.searchLists(userId, query)
+		if err != nil {
+			return []SearchResult{}, err
+		}
+
+		for _, list := range lists {
+			results = append(results, SearchResult{
+				Type:    "list",
+				Id:      list.Id,
+				ListId:  list.Id,
+				Url:     list.Url,
+				Title:   list.Name,
+				Snippet: util.Highlight(list.Name, query.Terms, 60),
+				Score:   list.Score,
+			})
+		}
+	}
+
+	// ranking the results, the best match comes first
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+
+	return results, nil
+}
+
+// a task or a list with the relevance of the search
+type scoredTask struct {
+	Task
+	Score float64
+}
+
+type scoredList struct {
+	List
+	Score float64
+}
+
+func (s *GormStore) searchTasks(userId int, query SearchQuery) ([]scoredTask, error) {
+	// only the tasks from the lists of the user
+	tx := s.db.Model(&Task{}).Where("list_id IN (?)", accessibleListIds(s.db, userId))
+
+	// matching the search terms
+	if len(query.Terms) > 0 {
+		tx = matchTerms(tx, query.Terms, "title", "description")
+	}
+
+	// applying the filters
+	if query.Done != nil {
+		tx = tx.Where("is_done = ?", *query.Done)
+	}
+	if query.ListUrl != "" {
+		tx = tx.Where("list_id IN (?)", s.db.Model(&List{}).Select("id").Where("url = ?", query.ListUrl))
+	}
+	if query.CreatedAfter != nil {
+		tx = tx.Where("created_at >= ?", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		tx = tx.Where("created_at < ?", *query.CreatedBefore)
+	}
+	if query.DueAfter != nil {
+		tx = tx.Where("due_date >= ?", *query.DueAfter)
+	}
+	if query.DueBefore != nil {
+		tx = tx.Where("due_date < ?", *query.DueBefore)
+	}
+
+	// ranking every matching task in the db, the newest comes first from the equally relevant ones
+	ranked, err := rank(tx, query.Terms, "created_at DESC", "title", "description")
+	if err != nil {
+		return []scoredTask{}, err
+	}
+
+	var tasks []Task
+	if err := s.db.Where("id IN ?", ids(ranked)).Find(&tasks).Error; err != nil {
+		return []scoredTask{}, err
+	}
+
+	// putting the tasks in the order of the ranking
+	byId := map[int]Task{}
+	for _, task := range tasks {
+		byId[task.Id] = task
+	}
+
+	scored := []scoredTask{}
+	for _, r := range ranked {
+		if task, ok := byId[r.Id]; ok {
+			scored = append(scored, scoredTask{Task: task, Score: r.Score})
+		}
+	}
+
+	return scored, nil
+}
+
+func (s *GormStore) searchLists(userId int, query SearchQuery) ([]scoredList, error) {
+	// matching the search terms in the names of the lists of the user
+	tx := matchTerms(s.db.Model(&List{}).Where("id IN (?)", accessibleListIds(s.db, userId)), query.Terms, "name")
+
+	ranked, err := rank(tx, query.Terms, "id DESC", "name")
+	if err != nil {
+		return []scoredList{}, err
+	}
+
+	var lists []List
+	if err := s.db.Where("id IN ?", ids(ranked)).Find(&lists).Error; err != nil {
+		return []scoredList{}, err
+	}
+
+	// putting the lists in the order of the ranking
+	byId := map[int]List{}
+	for _, list := range lists {
+		byId[list.Id] = list
+	}
+
+	scored := []scoredList{}
+	for _, r := range ranked {
+		if list, ok := byId[r.Id]; ok {
+			scored = append(scored, scoredList{List: list, Score: r.Score})
+		}
+	}
+
+	return scored, nil
+}
+
+// the id and the relevance of a matching row
+type ranking struct {
+	Id    int
+	Score float64
+}
+
+// returns the ids of the best matching rows with their relevance, ordered in the db,
+// so every match is ranked and not only the newest ones
+func rank(tx *gorm.DB, terms []string, tiebreak string, columns ...string) ([]ranking, error) {
+	expr, values := relevance(tx, terms, columns...)
+
+	var ranked []ranking
+	err := tx.Select("id, "+expr+" AS score", values...).
+		Order("score DESC, " + tiebreak).
+		Limit(maxSearchResults).
+		Scan(&ranked).Error
+	if err != nil {
+		return []ranking{}, err
+	}
+
+	return ranked, nil
+}
+
+func ids(ranked []ranking) []int {
+	ids := []int{}
+	for _, r := range ranked {
+		ids = append(ids, r.Id)
+	}
+
+	return ids
+}
+
+// returns the expression of the relevance of the columns to the terms
+// MySQL calculates it from the FULLTEXT index, the other databases count how many times
+// the terms appear in the columns, where the first column counts double, like the title of a task
+func relevance(db *gorm.DB, terms []string, columns ...string) (string, []interface{}) {
+	if len(terms) == 0 {
+		return "0", nil
+	}
+
+	if db.Dialector.Name() == "mysql" {
+		return "MATCH(" + strings.Join(columns, ", ") + ") AGAINST (? IN BOOLEAN MODE)", []interface{}{booleanQuery(terms)}
+	}
+
+	counts := []string{}
+	values := []interface{}{}
+	for i, column := range columns {
+		weight := "1"
+		if i == 0 {
+			weight = "2"
+		}
+
+		for _, term := range terms {
+			// the length of the removed occurrences divided by the length of the term
+			counts = append(counts, weight+" * (LENGTH("+column+") - LENGTH(REPLACE(LOWER("+column+"), ?, ''))) / "+strconv.Itoa(utf8.RuneCountInString(term)))
+			values = append(values, term)
+		}
+	}
+
+	return "(" + strings.Join(counts, " + ") + ")", values
+}
+
+// adds the condition that any of the terms matches any of the columns,
+// using the FULLTEXT indexes on MySQL and LIKE on the other databases
+func matchTerms(tx *gorm.DB, terms []string, columns ...string) *gorm.DB {
+	if tx.Dialector.Name() == "mysql" {
+		return tx.Where("MATCH("+strings.Join(columns, ", ")+") AGAINST (? IN BOOLEAN MODE)", booleanQuery(terms))
+	}
+
+	// any of the terms can match any of the columns
+	conditions := []string{}
+	values := []interface{}{}
+	for _, term := range terms {
+		for _, column := range columns {
+			conditions = append(conditions, "LOWER("+column+") LIKE ?")
+			values = append(values, "%"+term+"%")
+		}
+	}
+
+	return tx.Where("("+strings.Join(conditions, " OR ")+")", values...)
+}
+
+// every term is searched as a prefix in the boolean mode of MySQL
+func booleanQuery(terms []string) string {
+	words := make([]string, len(terms))
+	for i, term := range terms {
+		words[i] = term + "*"
+	}
+
+	return strings.Join(words, " ")
+}
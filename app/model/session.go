@@ -0,0 +1,177 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+	"gorm.io/gorm"
+)
+
+// how often the last-seen time of a session is written to the db
+const lastSeenInterval = time.Minute
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// defining a model struct
+// a session is created on login and lives as long as its refresh token
+type Session struct {
+	Id           int        `json:"id" gorm:"primaryKey" example:"1"`
+	UserId       int        `json:"-" gorm:"not null;index"`
+	RefreshHash  string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	PreviousHash string     `json:"-" gorm:"size:64;index"`
+	Device       string     `json:"device" gorm:"size:255" example:"Mozilla/5.0 (X11; Linux x86_64) Firefox/102.0"`
+	Ip           string     `json:"ip" gorm:"size:45" example:"127.0.0.1"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"not null" example:"2022-07-20T16:38:19Z"`
+	LastSeenAt   time.Time  `json:"lastSeenAt" gorm:"not null" example:"2022-07-21T08:12:45Z"`
+	ExpiresAt    time.Time  `json:"expiresAt" gorm:"not null" example:"2022-08-20T16:38:19Z"`
+	RevokedAt    *time.Time `json:"-" gorm:"default:null"`
+	Current      bool       `json:"current" gorm:"-" example:"true"`
+}
+
+func (session Session) IsActive(now time.Time) bool {
+	return session.RevokedAt == nil && session.ExpiresAt.After(now)
+}
+
+// creates a session for the user and returns it with the raw refresh token
+func (s *GormStore) CreateSession(userId int, device string, ip string, ttl time.Duration) (Session, string, error) {
+	refresh, err := util.GenerateToken()
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	// the user agent can be longer than the column
+	if len(device) > 255 {
+		device = device[:255]
+	}
+
+	now := time.Now().UTC()
+	session := Session{
+		UserId:      userId,
+		RefreshHash: util.HashToken(refresh),
+		Device:      device,
+		Ip:          ip,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	tx := s.db.Create(&session)
+	return session, refresh, tx.Error
+}
+
+// replaces the refresh token of the session and returns the session with the new token
+// if an already replaced token is used again, it has probably been stolen, so the session is revoked
+func (s *GormStore) RefreshSession(refresh string, ip string, ttl time.Duration) (Session, string, error) {
+	hash := util.HashToken(refresh)
+	now := time.Now().UTC()
+
+	var session Session
+	tx := s.db.Where("refresh_hash = ?", hash).Limit(1).Find(&session)
+	if tx.Error != nil {
+		return Session{}, "", tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		// checking whether it's a replaced token
+		tx = s.db.Where("previous_hash = ?", hash).Limit(1).Find(&session)
+		if tx.Error == nil && tx.RowsAffected > 0 {
+			s.RevokeSession(session.UserId, session.Id)
+		}
+
+		return Session{}, "", ErrSessionNotFound
+	}
+
+	if !session.IsActive(now) {
+		return Session{}, "", ErrSessionNotFound
+	}
+
+	newRefresh, err := util.GenerateToken()
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	// the refresh_hash condition makes sure concurrent requests can't rotate the same token twice
+	tx = s.db.Model(&Session{}).
+		Where("id = ? AND refresh_hash = ?", session.Id, hash).
+		Updates(map[string]interface{}{
+			"refresh_hash":  util.HashToken(newRefresh),
+			"previous_hash": hash,
+			"ip":            ip,
+			"last_seen_at":  now,
+			"expires_at":    now.Add(ttl),
+		})
+	if tx.Error != nil {
+		return Session{}, "", tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return Session{}, "", ErrSessionNotFound
+	}
+
+	session.Ip = ip
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(ttl)
+	return session, newRefresh, nil
+}
+
+func (s *GormStore) GetSessionById(id int) (Session, error) {
+	var session Session
+	tx := s.db.Where("id = ?", id).First(&session)
+	return session, tx.Error
+}
+
+// returns the active sessions of the user, the most recently used first
+func (s *GormStore) GetSessions(userId int) ([]Session, error) {
+	var sessions []Session
+	tx := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now().UTC()).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+	return sessions, tx.Error
+}
+
+// updates the last-seen time of the session, at most once every minute
+func (s *GormStore) TouchSession(session Session, ip string) {
+	now := time.Now().UTC()
+	if now.Sub(session.LastSeenAt) < lastSeenInterval && session.Ip == ip {
+		return
+	}
+
+	s.db.Model(&Session{}).Where("id = ?", session.Id).Updates(map[string]interface{}{
+		"ip":           ip,
+		"last_seen_at": now,
+	})
+}
+
+// revokes a session of the user
+func (s *GormStore) RevokeSession(userId int, id int) error {
+	tx := s.db.Model(&Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).
+		Update("revoked_at", time.Now().UTC())
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// revokes every session of the user, except the one with the given id
+func (s *GormStore) RevokeSessions(userId int, exceptId int) error {
+	return revokeSessions(s.db, userId, exceptId)
+}
+
+func revokeSessions(tx *gorm.DB, userId int, exceptId int) error {
+	return tx.Model(&Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userId, exceptId).
+		Update("revoked_at", time.Now().UTC()).Error
+}
+
+// revokes the session of the refresh token
+func (s *GormStore) RevokeRefreshToken(refresh string) error {
+	tx := s.db.Model(&Session{}).
+		Where("refresh_hash = ? AND revoked_at IS NULL", util.HashToken(refresh)).
+		Update("revoked_at", time.Now().UTC())
+	return tx.Error
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+)
+
+// sqlite stores the times as text, so they can only be compared in the queries
+// if they are in the same time zone, like MySQL and PostgreSQL do it
+const sqliteDriver = "sqlite_utc"
+
+func init() {
+	sql.Register(sqliteDriver, utcDriver{&gosqlite.Driver{}})
+}
+
+// a driver that converts every time to UTC before it's sent to the database
+type utcDriver struct {
+	driver.Driver
+}
+
+func (d utcDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return utcConn{conn}, nil
+}
+
+type utcConn struct {
+	driver.Conn
+}
+
+// converting the time arguments, the rest is left to the default conversion
+func (c utcConn) CheckNamedValue(value *driver.NamedValue) error {
+	switch t := value.Value.(type) {
+	case time.Time:
+		value.Value = t.UTC()
+	case *time.Time:
+		if t != nil {
+			value.Value = t.UTC()
+		}
+	}
+
+	return driver.ErrSkip
+}
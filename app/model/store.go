@@ -0,0 +1,153 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// the stores the controllers use to reach the db,
+// so the handlers don't depend on a db connection and can be tested with a fake
+
+type UserStore interface {
+	GetUserById(id int) (User, error)
+	GetUserByEmail(email string) (User, error)
+	ExistsByEmail(email string) bool
+	Register(user User) (User, error)
+	EditUser(user User) error
+	SetPassword(id int, password string, keepSessionId int) error
+	ResetPassword(id int, password string) error
+	ConfirmEmail(id int) error
+	EnableUser(id int) error
+
+	StartTotp(userId int) (string, error)
+	EnableTotp(user User, code string) ([]string, error)
+	DisableTotp(userId int) error
+	VerifySecondFactor(user User, code string) (bool, error)
+
+	LoginWithIdentity(provider string, subject string, email string, name string) (User, error)
+}
+
+type SessionStore interface {
+	CreateSession(userId int, device string, ip string, ttl time.Duration) (Session, string, error)
+	RefreshSession(refresh string, ip string, ttl time.Duration) (Session, string, error)
+	GetLoggedInSession(c *gin.Context) (Session, error)
+	GetSessions(userId int) ([]Session, error)
+	RevokeSession(userId int, id int) error
+	RevokeSessions(userId int, exceptId int) error
+	RevokeRefreshToken(refresh string) error
+}
+
+// the single-use tokens of the emails and the second factor
+type TokenStore interface {
+	CreateToken(userId int, purpose string, ttl time.Duration) (string, error)
+	UseToken(raw string, purpose string) (Token, error)
+	CheckToken(raw string, purpose string) (Token, error)
+	FailToken(id int) error
+	LastTokenCreatedAt(userId int, purpose string) (time.Time, bool)
+}
+
+// the personal access tokens
+type AccessTokenStore interface {
+	GetAccessTokens(userId int) ([]AccessToken, error)
+	CreateAccessToken(userId int, create NewAccessToken) (AccessToken, error)
+	DeleteAccessToken(userId int, id int) (bool, error)
+	AuthenticateAccessToken(raw string) (AccessToken, error)
+}
+
+// the members belong to the lists, because they decide who can access a list
+type ListStore interface {
+	GetLists(userId int, filter ListFilter, sort string, page Page) ([]List, PageInfo, error)
+	GetListByUrl(url string) (List, error)
+	ListExists(id int) (List, bool)
+	CreateList(list List) (List, error)
+	EditList(list List) (List, error)
+	MoveList(userId int, id int, targetId int, after bool) error
+	DeleteList(id int) error
+
+	HasRole(listId int, userId int, role string) bool
+	GetMembers(listId int) ([]Member, error)
+	GetMemberById(id int) (Member, error)
+	IsMember(listId int, userId int) bool
+	CreateMember(member Member) (Member, error)
+	EditMember(member Member) (Member, error)
+	DeleteMember(id int) error
+}
+
+type TaskStore interface {
+	GetTasks(listId int, userId int, filter TaskFilter, sort string, page Page) ([]Task, PageInfo, error)
+	GetTopPriorityTasks(userId int, limit int) ([]Task, error)
+	GetOverdueTasks(userId int, now time.Time) ([]Task, error)
+	GetTasksDueBetween(userId int, from time.Time, to time.Time) ([]Task, error)
+	GetTaskByUrl(url string, userId int) (Task, error)
+	TaskExists(id int) (Task, bool)
+	CreateTask(task Task) (Task, error)
+	EditTask(task Task) (Task, error)
+	ChangeIsDone(id int, completeItems bool, userId int, loc *time.Location) (Task, error)
+	MoveTask(id int, targetId int, after bool) (Task, error)
+	MoveTasks(ids []int, listId int, regenerateUrl bool, userId int) ([]Task, error)
+	CopyTasks(ids []int, listId int, userId int) ([]Task, error)
+	DeleteTask(id int) error
+}
+
+type TagStore interface {
+	GetTags(ownerId int) ([]Tag, error)
+	GetTagById(id int) (Tag, error)
+	TagNameExists(ownerId int, name string, exceptId int) bool
+	OwnsTags(ownerId int, tags []Tag) bool
+	CreateTag(tag Tag) (Tag, error)
+	EditTag(tag Tag) (Tag, error)
+	DeleteTag(id int) error
+	MergeTag(fromId int, intoId int) error
+	SetTaskTags(taskId int, ownerId int, tags []Tag) error
+	GetTaskTags(taskId int, userId int) ([]Tag, error)
+	GetTasksByTags(userId int, names []string) ([]Task, error)
+}
+
+// the checklist items of the tasks
+type ItemStore interface {
+	GetItems(taskId int) ([]Item, error)
+	GetItemById(id int) (Item, error)
+	CreateItem(item Item) (Item, error)
+	EditItem(item Item) (Item, error)
+	DeleteItem(id int) error
+}
+
+type Searcher interface {
+	Search(userId int, query SearchQuery) ([]SearchResult, error)
+}
+
+// the deleted lists and tasks, until they are restored or purged
+type TrashStore interface {
+	GetTrash(userId int) ([]TrashItem, error)
+	GetListWithTrashed(id int) (List, error)
+	GetTaskWithTrashed(id int) (Task, error)
+	RestoreList(id int) (List, error)
+	RestoreTask(id int, userId int) (Task, error)
+	PurgeList(id int) error
+	PurgeTask(id int) error
+}
+
+// the stores implemented with gorm
+type GormStore struct {
+	db *gorm.DB
+}
+
+// making sure the GormStore implements every store
+var (
+	_ UserStore        = (*GormStore)(nil)
+	_ ListStore        = (*GormStore)(nil)
+	_ TaskStore        = (*GormStore)(nil)
+	_ TrashStore       = (*GormStore)(nil)
+	_ SessionStore     = (*GormStore)(nil)
+	_ TokenStore       = (*GormStore)(nil)
+	_ AccessTokenStore = (*GormStore)(nil)
+	_ TagStore         = (*GormStore)(nil)
+	_ ItemStore        = (*GormStore)(nil)
+	_ Searcher         = (*GormStore)(nil)
+)
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
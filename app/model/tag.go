@@ -0,0 +1,263 @@
+package model
+
+import (
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// a label of a user that can be attached to tasks in any list
+type Tag struct {
+	Id      int    `json:"id" gorm:"primaryKey" example:"1"`
+	OwnerId int    `json:"ownerId" gorm:"not null;column:owner_id;uniqueIndex:idx_tag_owner_name" example:"1"`
+	Name    string `json:"name" gorm:"not null;size:32;uniqueIndex:idx_tag_owner_name" example:"urgent"`
+	Color   string `json:"color" gorm:"not null;size:7" example:"#ef4444"`
+
+	Owner *User `json:"-" gorm:"foreignKey:OwnerId;constraint:OnDelete:CASCADE"`
+}
+
+// the join table between the tasks and the tags
+type TaskTag struct {
+	TaskId int `gorm:"primaryKey;column:task_id"`
+	TagId  int `gorm:"primaryKey;column:tag_id"`
+}
+
+// defining a MergeTags for the documentation
+type MergeTags struct {
+	IntoId int `json:"intoId" example:"2"`
+}
+
+func (tag Tag) Validate() (bool, string) {
+	// if the name is empty
+	if len(tag.Name) < 1 {
+		return false, "The name can not be empty."
+	}
+
+	// if the name is too long
+	if len(tag.Name) > 32 {
+		return false, "The name can be maximum 32 characters long."
+	}
+
+	// creating the regexp for the color validation
+	r, _ := regexp.Compile("^#[0-9a-fA-F]{6}$")
+
+	// validating the color
+	if !r.MatchString(tag.Color) {
+		return false, "The color has to be a hex color like #ef4444."
+	}
+
+	return true, ""
+}
+
+func (s *GormStore) GetTags(ownerId int) ([]Tag, error) {
+	var tags []Tag
+
+	// getting the tags of the user from the db
+	// the result-set should be ordered in ascending order by name
+	tx := s.db.Where("owner_id = ?", ownerId).Order("name ASC").Find(&tags)
+	if tx.Error != nil {
+		return []Tag{}, tx.Error
+	}
+
+	return tags, nil
+}
+
+func (s *GormStore) GetTagById(id int) (Tag, error) {
+	// getting the tag from the db by id
+	var tag Tag
+	tx := s.db.Where("id = ?", id).First(&tag)
+	return tag, tx.Error
+}
+
+func (s *GormStore) TagNameExists(ownerId int, name string, exceptId int) bool {
+	// counting the other tags of the user with the same name
+	var count int64
+	s.db.Model(&Tag{}).Where("owner_id = ? AND name = ? AND id <> ?", ownerId, name, exceptId).Count(&count)
+	return count > 0
+}
+
+func (s *GormStore) OwnsTags(ownerId int, tags []Tag) bool {
+	// nothing to check
+	ids := tagIds(tags)
+	if len(ids) == 0 {
+		return true
+	}
+
+	// every tag has to belong to the user
+	var count int64
+	s.db.Model(&Tag{}).Where("owner_id = ? AND id IN ?", ownerId, ids).Count(&count)
+	return int(count) == len(ids)
+}
+
+func (s *GormStore) CreateTag(tag Tag) (Tag, error) {
+	// creating the tag in the db
+	tx := s.db.Create(&tag)
+	return tag, tx.Error
+}
+
+func (s *GormStore) EditTag(tag Tag) (Tag, error) {
+	// saving the tag in the db
+	tx := s.db.Save(&tag)
+	return tag, tx.Error
+}
+
+func (s *GormStore) DeleteTag(id int) error {
+	// deleting the tag from the db, the foreign keys delete its links to the tasks
+	tx := s.db.Unscoped().Delete(&Tag{}, id)
+	return tx.Error
+}
+
+func (s *GormStore) MergeTag(fromId int, intoId int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// the tasks that already have the target tag
+		var tagged []int
+		if err := tx.Model(&TaskTag{}).Where("tag_id = ?", intoId).Pluck("task_id", &tagged).Error; err != nil {
+			return err
+		}
+
+		// moving the links of the merged tag to the target tag,
+		// except for the tasks that already have it
+		move := tx.Model(&TaskTag{}).Where("tag_id = ?", fromId)
+		if len(tagged) > 0 {
+			move = move.Where("task_id NOT IN ?", tagged)
+		}
+		if err := move.Update("tag_id", intoId).Error; err != nil {
+			return err
+		}
+
+		// deleting the remaining links and the merged tag
+		if err := tx.Where("tag_id = ?", fromId).Delete(&TaskTag{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&Tag{}, fromId).Error
+	})
+}
+
+func (s *GormStore) SetTaskTags(taskId int, ownerId int, tags []Tag) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// removing the tags of the user from the task,
+		// the tags of the other members are kept
+		owned := tx.Model(&Tag{}).Select("id").Where("owner_id = ?", ownerId)
+		if err := tx.Where("task_id = ? AND tag_id IN (?)", taskId, owned).Delete(&TaskTag{}).Error; err != nil {
+			return err
+		}
+
+		// nothing to add
+		if len(tags) == 0 {
+			return nil
+		}
+
+		// adding the new tags to the task
+		links := []TaskTag{}
+		for _, id := range tagIds(tags) {
+			links = append(links, TaskTag{TaskId: taskId, TagId: id})
+		}
+
+		return tx.Create(&links).Error
+	})
+}
+
+func (s *GormStore) GetTaskTags(taskId int, userId int) ([]Tag, error) {
+	return taskTags(s.db, taskId, userId)
+}
+
+func taskTags(db *gorm.DB, taskId int, userId int) ([]Tag, error) {
+	var tags []Tag
+
+	// getting the tags of the user on the task from the db
+	tx := db.Joins("JOIN task_tags ON task_tags.tag_id = tags.id").
+		Where("task_tags.task_id = ? AND tags.owner_id = ?", taskId, userId).
+		Order("tags.name ASC").
+		Find(&tags)
+	if tx.Error != nil {
+		return []Tag{}, tx.Error
+	}
+
+	return tags, nil
+}
+
+func (s *GormStore) GetTasksByTags(userId int, names []string) ([]Task, error) {
+	var tasks []Task
+
+	// the tasks that have every specified tag of the user
+	tagged := s.db.Model(&TaskTag{}).
+		Select("task_tags.task_id").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("tags.owner_id = ? AND tags.name IN ?", userId, names).
+		Group("task_tags.task_id").
+		Having("COUNT(DISTINCT tags.id) = ?", len(names))
+
+	// getting the tagged tasks from the lists of the user
+	// the result-set should be ordered in descending order by created_at
+	tx := preloadTags(s.db, userId).
+		Where("list_id IN (?)", accessibleListIds(s.db, userId)).
+		Where("id IN (?)", tagged).
+		Order("created_at DESC").
+		Find(&tasks)
+	if tx.Error != nil {
+		return []Task{}, tx.Error
+	}
+
+	// counting the checklist items of the tasks
+	err := loadProgress(s.db, tasks)
+	return tasks, err
+}
+
+// the tags are private, so the tasks are loaded with the tags of one user only,
+// the other members of a shared list don't see them
+func preloadTags(db *gorm.DB, userId int) *gorm.DB {
+	return db.Preload("Tags", "owner_id = ?", userId)
+}
+
+// copies the tags of the user from one task to another
+// the tags of the other users are private, so they aren't copied
+func copyTags(tx *gorm.DB, fromTaskId int, toTaskId int, userId int) error {
+	var links []TaskTag
+
+	// getting the tags of the user on the original task
+	ownTags := tx.Model(&Tag{}).Select("id").Where("owner_id = ?", userId)
+	if err := tx.Where("task_id = ? AND tag_id IN (?)", fromTaskId, ownTags).Find(&links).Error; err != nil {
+		return err
+	}
+
+	// nothing to copy
+	if len(links) == 0 {
+		return nil
+	}
+
+	// linking the tags to the new task
+	for i := range links {
+		links[i].TaskId = toTaskId
+	}
+
+	return tx.Create(&links).Error
+}
+
+// deletes the tags of the tasks whose owners can't access the list
+func dropInaccessibleTags(tx *gorm.DB, taskIds []int, listId int) error {
+	// the users who can access the list are its owner and its members
+	owner := tx.Unscoped().Model(&List{}).Select("owner_id").Where("id = ?", listId)
+	members := tx.Model(&Member{}).Select("user_id").Where("list_id = ?", listId)
+
+	inaccessible := tx.Model(&Tag{}).Select("id").
+		Where("owner_id NOT IN (?)", owner).
+		Where("owner_id NOT IN (?)", members)
+
+	return tx.Where("task_id IN ? AND tag_id IN (?)", taskIds, inaccessible).Delete(&TaskTag{}).Error
+}
+
+func tagIds(tags []Tag) []int {
+	// collecting the unique ids of the tags
+	seen := map[int]bool{}
+	ids := []int{}
+
+	for _, tag := range tags {
+		if !seen[tag.Id] {
+			seen[tag.Id] = true
+			ids = append(ids, tag.Id)
+		}
+	}
+
+	return ids
+}
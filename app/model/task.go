@@ -2,21 +2,105 @@
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/0l1v3rr/todo/app/util"
+	"gorm.io/gorm"
 )
 
 // task struct
 type Task struct {
-	Id          int       `json:"id" gorm:"primaryKey" example:"1"`
-	ListId      int       `json:"listId" gorm:"not null;column:list_id" example:"1"`
-	CreatedById int       `json:"createdById" gorm:"not null;column:created_by_id" example:"1"`
-	Title       string    `json:"title" gorm:"not null" example:"Task"`
-	Url         string    `json:"url" gorm:"not null;unique" example:"task-1"`
-	Description string    `json:"description" example:"This is a great task!"`
-	IsDone      bool      `json:"isDone" gorm:"not null;column:is_done" example:"true"`
-	CreatedAt   time.Time `json:"createdAt" gorm:"not null;column:created_at" example:"2022-06-29 13:27"`
+	Id          int        `json:"id" gorm:"primaryKey" example:"1"`
+	ListId      int        `json:"listId" gorm:"not null;column:list_id" example:"1"`
+	CreatedById *int       `json:"createdById" gorm:"column:created_by_id" example:"1"`
+	Title       string     `json:"title" gorm:"not null" example:"Task"`
+	Url         string     `json:"url" gorm:"not null;unique" example:"task-1"`
+	Description string     `json:"description" example:"This is a great task!"`
+	IsDone      bool       `json:"isDone" gorm:"not null;column:is_done" example:"true"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"not null;column:created_at" example:"2022-06-29 13:27"`
+	StartDate   *time.Time `json:"startDate" gorm:"column:start_date" example:"2022-07-01T08:00:00Z"`
+	DueDate     *time.Time `json:"dueDate" gorm:"column:due_date;index" example:"2022-07-03T17:00:00Z"`
+	Recurrence  string     `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO,FR"`
+	Priority    int        `json:"priority" gorm:"not null;default:0;index" example:"3"`
+	Position    float64    `json:"position" gorm:"not null;default:0" example:"1024"`
+	Progress    Progress   `json:"progress" gorm:"-"`
+	Tags        []Tag      `json:"tags" gorm:"many2many:task_tags"`
+
+	// the deleted tasks are kept in the trash until they are restored or purged,
+	// the ones deleted together with their list are restored with it
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+	DeletedWithList bool           `json:"-" gorm:"not null;default:false"`
+
+	// the task is deleted with its list, and it's kept without its creator
+	List      *List `json:"-" gorm:"foreignKey:ListId;constraint:OnDelete:CASCADE"`
+	CreatedBy *User `json:"-" gorm:"foreignKey:CreatedById;constraint:OnDelete:SET NULL"`
+}
+
+// the priority levels of a task
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// the keys of the orders the tasks of a list can be sorted by
+var (
+	taskIdKey       = sortKey{Column: "id", Kind: kindInt}
+	taskCreatedKey  = sortKey{Column: "created_at", Desc: true, Kind: kindTime}
+	taskNoDueKey    = sortKey{Column: "CASE WHEN due_date IS NULL THEN 1 ELSE 0 END", Kind: kindInt}
+	taskDueKey      = sortKey{Column: "due_date", Kind: kindTime, Nullable: true}
+	taskPriorityKey = sortKey{Column: "priority", Desc: true, Kind: kindInt}
+	taskPositionKey = sortKey{Column: "position", Kind: kindFloat}
+)
+
+var taskSorts = map[string][]sortKey{
+	"position": {taskPositionKey, taskCreatedKey, taskIdKey},
+	"created":  {taskCreatedKey, taskIdKey},
+	"due":      {taskNoDueKey, taskDueKey, taskCreatedKey, taskIdKey},
+	"priority": {taskPriorityKey, taskNoDueKey, taskDueKey, taskCreatedKey, taskIdKey},
+}
+
+// the filters of the tasks of a list
+type TaskFilter struct {
+	IsDone        *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Title         string
+}
+
+func IsValidTaskSort(sort string) bool {
+	_, ok := taskSorts[sort]
+	return ok
+}
+
+// returns the values of the sort keys of the task
+func (task Task) sortValues(keys []sortKey) []interface{} {
+	values := make([]interface{}, len(keys))
+
+	for i, key := range keys {
+		switch key {
+		case taskIdKey:
+			values[i] = task.Id
+		case taskCreatedKey:
+			values[i] = task.CreatedAt
+		case taskNoDueKey:
+			values[i] = 0
+			if task.DueDate == nil {
+				values[i] = 1
+			}
+		case taskDueKey:
+			values[i] = nullableTime(task.DueDate)
+		case taskPriorityKey:
+			values[i] = task.Priority
+		case taskPositionKey:
+			values[i] = task.Position
+		}
+	}
+
+	return values
 }
 
 func (task Task) Validate() (bool, string) {
@@ -35,28 +119,151 @@ func (task Task) Validate() (bool, string) {
 		return false, "The description can be maximum 256 characters long."
 	}
 
+	// if the task would start after it is due
+	if task.StartDate != nil && task.DueDate != nil && task.StartDate.After(*task.DueDate) {
+		return false, "The start date can not be after the due date."
+	}
+
+	// if the priority does not exist
+	if task.Priority < PriorityNone || task.Priority > PriorityUrgent {
+		return false, "The priority has to be between 0 (none) and 4 (urgent)."
+	}
+
+	// if the recurrence rule is not valid
+	if task.Recurrence != "" {
+		if _, err := util.ParseRecurrence(task.Recurrence); err != nil {
+			return false, "The recurrence rule is not valid: " + err.Error()
+		}
+	}
+
 	// if the task is valid
 	return true, ""
 }
 
-func GetTasks(listId int) ([]Task, error) {
+func (s *GormStore) GetTasks(listId int, userId int, filter TaskFilter, sort string, page Page) ([]Task, PageInfo, error) {
 	var tasks []Task
 
+	// falling back to the default order if the sort is unknown
+	keys, ok := taskSorts[sort]
+	if !ok {
+		keys = taskSorts["position"]
+	}
+
 	// getting the tasks from the db where the list id is the specified
-	// the result-set should be ordered in descending order by created_at
-	tx := DB.Where("list_id = ?", listId).Order("created_at DESC").Find(&tasks)
+	tx := preloadTags(s.db, userId).Where("list_id = ?", listId)
+
+	// applying the filters
+	if filter.IsDone != nil {
+		tx = tx.Where("is_done = ?", *filter.IsDone)
+	}
+	if filter.CreatedAfter != nil {
+		tx = tx.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		tx = tx.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Title != "" {
+		tx = tx.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(filter.Title)+"%")
+	}
+
+	// the result-set should be ordered by the specified sort and
+	// should only contain the rows of the requested page
+	tx, backward, err := paginate(tx, keys, page)
+	if err != nil {
+		return []Task{}, PageInfo{}, err
+	}
+
+	tx = tx.Find(&tasks)
+	if tx.Error != nil {
+		return []Task{}, PageInfo{}, tx.Error
+	}
+
+	// removing the extra row and restoring the order of a previous page
+	hasMore := page.Limit > 0 && len(tasks) > page.Limit
+	if hasMore {
+		tasks = tasks[:page.Limit]
+	}
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	// creating the cursors of the next and the previous pages
+	var first, last []interface{}
+	if len(tasks) > 0 {
+		first, last = tasks[0].sortValues(keys), tasks[len(tasks)-1].sortValues(keys)
+	}
+	info := pageInfo(page, backward, hasMore, first, last)
+
+	// counting the checklist items of the tasks
+	err = loadProgress(s.db, tasks)
+	return tasks, info, err
+}
+
+func (s *GormStore) GetTopPriorityTasks(userId int, limit int) ([]Task, error) {
+	var tasks []Task
+
+	// getting the open prioritized tasks from the lists of the user
+	// the result-set should be ordered by priority, then due date, then created_at
+	tx := preloadTags(s.db, userId).
+		Where("list_id IN (?)", accessibleListIds(s.db, userId)).
+		Where("is_done = ? AND priority > ?", false, PriorityNone).
+		Order(orderBy(taskSorts["priority"])).
+		Limit(limit).
+		Find(&tasks)
+	if tx.Error != nil {
+		return []Task{}, tx.Error
+	}
+
+	// counting the checklist items of the tasks
+	err := loadProgress(s.db, tasks)
+	return tasks, err
+}
+
+func (s *GormStore) GetOverdueTasks(userId int, now time.Time) ([]Task, error) {
+	var tasks []Task
+
+	// getting the open tasks from the lists of the user that are past their due date
+	// the result-set should be ordered in ascending order by due_date
+	tx := preloadTags(s.db, userId).
+		Where("list_id IN (?)", accessibleListIds(s.db, userId)).
+		Where("is_done = ? AND due_date < ?", false, now).
+		Order("due_date ASC").
+		Find(&tasks)
+	if tx.Error != nil {
+		return []Task{}, tx.Error
+	}
+
+	// counting the checklist items of the tasks
+	err := loadProgress(s.db, tasks)
+	return tasks, err
+}
+
+func (s *GormStore) GetTasksDueBetween(userId int, from time.Time, to time.Time) ([]Task, error) {
+	var tasks []Task
+
+	// getting the open tasks from the lists of the user that are due in the [from, to) interval
+	// the result-set should be ordered in ascending order by due_date
+	tx := preloadTags(s.db, userId).
+		Where("list_id IN (?)", accessibleListIds(s.db, userId)).
+		Where("is_done = ? AND due_date >= ? AND due_date < ?", false, from, to).
+		Order("due_date ASC").
+		Find(&tasks)
 	if tx.Error != nil {
 		return []Task{}, tx.Error
 	}
 
-	return tasks, nil
+	// counting the checklist items of the tasks
+	err := loadProgress(s.db, tasks)
+	return tasks, err
 }
 
-func GetTaskById(id int) (Task, error) {
+func (s *GormStore) GetTaskById(id int) (Task, error) {
 	var task Task
 
 	// getting the task from the db by id
-	tx := DB.Where("id = ?", id).First(&task)
+	tx := s.db.Where("id = ?", id).First(&task)
 	if tx.Error != nil {
 		return Task{}, tx.Error
 	}
@@ -64,21 +271,24 @@ func GetTaskById(id int) (Task, error) {
 	return task, nil
 }
 
-func GetTaskByUrl(url string) (Task, error) {
+func (s *GormStore) GetTaskByUrl(url string, userId int) (Task, error) {
 	var task Task
 
-	// getting the task from the db by url
-	tx := DB.Where("url = ?", url).Find(&task)
+	// getting the task from the db by url with the tags of the user
+	tx := preloadTags(s.db, userId).Where("url = ?", url).Find(&task)
 	if tx.Error != nil {
 		return Task{}, tx.Error
 	}
 
-	return task, nil
+	// counting the checklist items of the task
+	tasks := []Task{task}
+	err := loadProgress(s.db, tasks)
+	return tasks[0], err
 }
 
-func TaskExists(id int) (Task, bool) {
+func (s *GormStore) TaskExists(id int) (Task, bool) {
 	// getting the task by id
-	task, err := GetTaskById(id)
+	task, err := s.GetTaskById(id)
 
 	// if the err is not nil, the task doesn't exist
 	if err != nil {
@@ -94,25 +304,36 @@ func TaskExists(id int) (Task, bool) {
 	return task, true
 }
 
-func CreateTask(task Task) (Task, error) {
+func (s *GormStore) CreateTask(task Task) (Task, error) {
 	// overriding the necessary values
-	task.Url = fmt.Sprintf("%s-%s", util.CreateUrlByTitle(task.Title), util.GenerateHash(8))
+	task.Url = createTaskUrl(task.Title)
 	task.CreatedAt = time.Now()
 
-	// creating the task in the db
-	tx := DB.Create(&task)
+	// the new task goes to the top of the list
+	task.Position = firstTaskPosition(s.db, task.ListId)
+
+	// storing the dates in UTC
+	task.StartDate = util.ToUTC(task.StartDate)
+	task.DueDate = util.ToUTC(task.DueDate)
+
+	// creating the task in the db, the tags are linked separately
+	tx := s.db.Omit("Tags").Create(&task)
 	return task, tx.Error
 }
 
-func EditTask(task Task) (Task, error) {
-	// saving the new task in the db
-	tx := DB.Save(&task)
+func (s *GormStore) EditTask(task Task) (Task, error) {
+	// storing the dates in UTC
+	task.StartDate = util.ToUTC(task.StartDate)
+	task.DueDate = util.ToUTC(task.DueDate)
+
+	// saving the new task in the db, the tags are linked separately
+	tx := s.db.Omit("Tags").Save(&task)
 	return task, tx.Error
 }
 
-func ChangeIsDone(id int) (Task, error) {
+func (s *GormStore) ChangeIsDone(id int, completeItems bool, userId int, loc *time.Location) (Task, error) {
 	// getting the task by id
-	task, err := GetTaskById(id)
+	task, err := s.GetTaskById(id)
 	if err != nil {
 		return Task{}, err
 	}
@@ -120,17 +341,126 @@ func ChangeIsDone(id int) (Task, error) {
 	// changing the isDone value to its opposite
 	task.IsDone = !task.IsDone
 
-	// saving the task in the db
-	task, err = EditTask(task)
+	// saving every change in one transaction
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// if a recurring task is done, the next occurrence has to be created
+		if task.IsDone && task.Recurrence != "" {
+			next, ok, err := nextOccurrence(task, loc)
+			if err != nil {
+				return err
+			}
+
+			// there is no next occurrence after the last one of the rule
+			if ok {
+				if err := tx.Create(&next).Error; err != nil {
+					return err
+				}
+
+				// the next occurrence gets a fresh copy of the checklist and the tags of the user
+				if err := copyItems(tx, task.Id, next.Id); err != nil {
+					return err
+				}
+
+				if err := copyTags(tx, task.Id, next.Id, userId); err != nil {
+					return err
+				}
+			}
+
+			// the rule moves to the next occurrence,
+			// so undoing the completion does not create another one
+			task.Recurrence = ""
+		}
+
+		// saving the task in the db
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+
+		// completing the checklist items of the task if requested
+		if task.IsDone && completeItems {
+			return tx.Model(&Item{}).Where("task_id = ?", task.Id).Update("is_done", true).Error
+		}
+
+		return nil
+	})
 	if err != nil {
 		return Task{}, err
 	}
 
-	return task, nil
+	// loading the tags and the progress of the task
+	task.Tags, err = taskTags(s.db, task.Id, userId)
+	if err != nil {
+		return Task{}, err
+	}
+
+	tasks := []Task{task}
+	err = loadProgress(s.db, tasks)
+	return tasks[0], err
+}
+
+// the weekdays and the time of the day of the rule are in the time zone of the user,
+// it returns false if the task was the last occurrence of the rule
+func nextOccurrence(task Task, loc *time.Location) (Task, bool, error) {
+	rule, err := util.ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return Task{}, false, err
+	}
+
+	// the task was the last one the COUNT allows
+	if rule.Count == 1 {
+		return Task{}, false, nil
+	}
+
+	// the next occurrence is calculated from the due date,
+	// or from the completion if there is no due date or the rule says so
+	now := time.Now()
+	base := now.In(loc)
+	if task.DueDate != nil && !rule.FromCompletion {
+		base = task.DueDate.In(loc)
+	}
+
+	// the same rule as the occurrences of the task show
+	pinned := rule.Pinned(base)
+	due := pinned.Next(base)
+
+	// the rule ends before the next occurrence
+	if rule.Ended(due) {
+		return Task{}, false, nil
+	}
+
+	// the next occurrence is a copy of the task with a new url and dates
+	next := task
+	next.Id = 0
+	next.IsDone = false
+	next.Url = createTaskUrl(task.Title)
+	next.CreatedAt = now
+	next.DueDate = util.ToUTC(&due)
+
+	// the pinned day is kept in the rule, so it's not lost after a short month,
+	// and the next occurrence has one less left from the COUNT
+	if pinned.Count != 0 {
+		pinned.Count--
+	}
+	if pinned.ByMonthDay != rule.ByMonthDay || pinned.Count != rule.Count {
+		next.Recurrence = pinned.String()
+	}
+
+	// the start date keeps its distance from the due date
+	if task.StartDate != nil {
+		start := due.Add(-base.Sub(*task.StartDate))
+		next.StartDate = util.ToUTC(&start)
+	}
+
+	return next, true, nil
+}
+
+func createTaskUrl(title string) string {
+	// the url is created from the title and a random hash
+	return fmt.Sprintf("%s-%s", util.CreateUrlByTitle(title), util.GenerateHash(8))
 }
 
-func DeleteTask(id int) error {
-	// deleting the task from the db
-	tx := DB.Unscoped().Delete(&Task{}, id)
+func (s *GormStore) DeleteTask(id int) error {
+	// moving the task to the trash, its checklist items and tags are kept for restoring it
+	tx := s.db.Delete(&Task{}, id)
 	return tx.Error
 }
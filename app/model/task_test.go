@@ -0,0 +1,172 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+
+	at := func(year int, month time.Month, day int, hour int, minute int) *time.Time {
+		d := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+		return &d
+	}
+
+	tests := []struct {
+		name      string
+		rule      string
+		loc       *time.Location
+		start     *time.Time
+		due       *time.Time
+		wantStart *time.Time
+		wantDue   *time.Time
+		wantRule  string
+	}{
+		{
+			name: "the start date moves with the due date",
+			rule: "FREQ=WEEKLY", loc: time.UTC,
+			start: at(2022, 7, 30, 9, 0), due: at(2022, 8, 1, 9, 0),
+			wantStart: at(2022, 8, 6, 9, 0), wantDue: at(2022, 8, 8, 9, 0), wantRule: "FREQ=WEEKLY",
+		},
+		{
+			name: "the day of the month is pinned at the end of a short month",
+			rule: "FREQ=MONTHLY", loc: time.UTC,
+			due:     at(2022, 1, 31, 9, 0),
+			wantDue: at(2022, 2, 28, 9, 0), wantRule: "FREQ=MONTHLY;BYMONTHDAY=31",
+		},
+		{
+			name: "the pinned day is used after the short month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31", loc: time.UTC,
+			due:     at(2022, 2, 28, 9, 0),
+			wantDue: at(2022, 3, 31, 9, 0), wantRule: "FREQ=MONTHLY;BYMONTHDAY=31",
+		},
+		{
+			name: "the next occurrence has one less from the COUNT",
+			rule: "FREQ=DAILY;INTERVAL=2;COUNT=3", loc: time.UTC,
+			due:     at(2022, 8, 1, 9, 0),
+			wantDue: at(2022, 8, 3, 9, 0), wantRule: "FREQ=DAILY;INTERVAL=2;COUNT=2",
+		},
+		{
+			name: "the occurrence on the date of UNTIL is created",
+			rule: "FREQ=DAILY;UNTIL=20220803", loc: time.UTC,
+			due:     at(2022, 8, 2, 9, 0),
+			wantDue: at(2022, 8, 3, 9, 0), wantRule: "FREQ=DAILY;UNTIL=20220803",
+		},
+		{
+			name: "the weekdays are in the time zone of the user",
+			rule: "FREQ=WEEKLY;BYDAY=TU", loc: budapest,
+			due:     at(2022, 8, 1, 22, 30),
+			wantDue: at(2022, 8, 8, 22, 30), wantRule: "FREQ=WEEKLY;BYDAY=TU",
+		},
+	}
+
+	for _, test := range tests {
+		task := Task{Id: 1, Title: "Chore", Url: "chore-1", IsDone: true, Recurrence: test.rule, StartDate: test.start, DueDate: test.due}
+
+		next, ok, err := nextOccurrence(task, test.loc)
+		if err != nil || !ok {
+			t.Errorf("%s: %v, %v, want the next occurrence", test.name, ok, err)
+			continue
+		}
+
+		if next.Id != 0 || next.IsDone || next.Url == task.Url || next.Title != task.Title {
+			t.Errorf("%s: next = %+v, want a new open copy of the task", test.name, next)
+		}
+		if !next.DueDate.Equal(*test.wantDue) {
+			t.Errorf("%s: due = %s, want %s", test.name, next.DueDate, test.wantDue)
+		}
+		if (next.StartDate == nil) != (test.wantStart == nil) || (test.wantStart != nil && !next.StartDate.Equal(*test.wantStart)) {
+			t.Errorf("%s: start = %v, want %v", test.name, next.StartDate, test.wantStart)
+		}
+		if next.Recurrence != test.wantRule {
+			t.Errorf("%s: rule = %s, want %s", test.name, next.Recurrence, test.wantRule)
+		}
+	}
+}
+
+func TestNextOccurrenceAfterTheEnd(t *testing.T) {
+	due := time.Date(2022, 8, 3, 9, 0, 0, 0, time.UTC)
+
+	for _, rule := range []string{"FREQ=DAILY;COUNT=1", "FREQ=DAILY;UNTIL=20220803", "FREQ=WEEKLY;UNTIL=20220803T090000Z"} {
+		task := Task{Title: "Chore", Recurrence: rule, DueDate: &due}
+
+		if next, ok, err := nextOccurrence(task, time.UTC); err != nil || ok {
+			t.Errorf("%s: next = %+v, %v, %v, want no next occurrence", rule, next, ok, err)
+		}
+	}
+}
+
+func TestNextOccurrenceFromCompletion(t *testing.T) {
+	due := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+
+	// the occurrence is counted from now, not from the due date in the past,
+	// and also for the tasks without a due date
+	for _, dueDate := range []*time.Time{&due, nil} {
+		task := Task{Title: "Water the plants", Recurrence: "FREQ=DAILY;INTERVAL=3;FROM=COMPLETION", DueDate: dueDate}
+
+		before := time.Now()
+		next, ok, err := nextOccurrence(task, time.UTC)
+		if err != nil || !ok {
+			t.Fatalf("%v, %v, want the next occurrence", ok, err)
+		}
+
+		if next.DueDate.Before(before.AddDate(0, 0, 3)) || next.DueDate.After(time.Now().AddDate(0, 0, 3)) {
+			t.Errorf("due = %s, want 3 days from now", next.DueDate)
+		}
+	}
+}
+
+func TestChangeIsDoneCreatesTheNextOccurrence(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Chore User")
+	list := createTestList(t, s, user.Id, "Chores")
+
+	due := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	task := createTestTask(t, s, list.Id, user.Id, "Take out the trash")
+	task.DueDate = &due
+	task.Recurrence = "FREQ=WEEKLY;COUNT=2"
+	if _, err := s.EditTask(task); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateItem(Item{TaskId: task.Id, Title: "Paper"}); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := s.ChangeIsDone(task.Id, true, user.Id, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done.IsDone || done.Recurrence != "" || done.Progress.Done != 1 {
+		t.Errorf("done = %+v, want it done without a rule and with its items done", done)
+	}
+
+	// the next occurrence is open with an open copy of the checklist
+	var next Task
+	if err := s.db.Where("list_id = ? AND id <> ?", list.Id, task.Id).First(&next).Error; err != nil {
+		t.Fatal(err)
+	}
+	if next.IsDone || next.Recurrence != "FREQ=WEEKLY;COUNT=1" || !next.DueDate.Equal(due.AddDate(0, 0, 7)) {
+		t.Errorf("next = %+v", next)
+	}
+
+	items, err := s.GetItems(next.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].IsDone {
+		t.Errorf("items of the next occurrence = %+v, want an open copy", items)
+	}
+
+	// the last occurrence doesn't create another one
+	if _, err := s.ChangeIsDone(next.Id, false, user.Id, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, s, &Task{}, "list_id = ?", list.Id); n != 2 {
+		t.Errorf("%d tasks in the list, want 2", n)
+	}
+}
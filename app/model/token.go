@@ -0,0 +1,146 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+	"gorm.io/gorm"
+)
+
+// the purposes of the single-use tokens
+const (
+	TokenVerify = "verify"
+	TokenReset  = "reset"
+	TokenEmail  = "email"
+	TokenMfa    = "mfa"
+)
+
+// how many times a token can be checked with a wrong code
+const maxTokenAttempts = 5
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// defining a model struct
+// only the hash of the token is stored in the db
+type Token struct {
+	Id        int        `gorm:"primaryKey"`
+	UserId    int        `gorm:"not null;index"`
+	Purpose   string     `gorm:"not null;size:16"`
+	Hash      string     `gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	Attempts  int        `gorm:"not null;default:0"`
+	CreatedAt time.Time  `gorm:"not null"`
+}
+
+// defining an EmailRequest for the documentation
+type EmailRequest struct {
+	Email string `json:"email" example:"johndoe@gmail.com"`
+}
+
+// defining a ResetPassword for the documentation
+type ResetPassword struct {
+	Token    string `json:"token" example:"qGfTnC0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"`
+	Password string `json:"password" example:"SuperSecret69"`
+}
+
+// creates a new token for the user and returns the raw value of it
+// the previous unused tokens with the same purpose are invalidated
+func (s *GormStore) CreateToken(userId int, purpose string, ttl time.Duration) (string, error) {
+	raw, err := util.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	token := Token{
+		UserId:    userId,
+		Purpose:   purpose,
+		Hash:      util.HashToken(raw),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := invalidateTokens(tx, userId, purpose, now); err != nil {
+			return err
+		}
+
+		return tx.Create(&token).Error
+	})
+
+	return raw, err
+}
+
+// marks the token as used and returns it
+// a token can only be used once, and only before it expires
+func (s *GormStore) UseToken(raw string, purpose string) (Token, error) {
+	var token Token
+	now := time.Now().UTC()
+
+	tx := s.db.Where("hash = ? AND purpose = ?", util.HashToken(raw), purpose).Limit(1).Find(&token)
+	if tx.Error != nil {
+		return Token{}, tx.Error
+	}
+	if tx.RowsAffected == 0 || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return Token{}, ErrInvalidToken
+	}
+
+	// the used_at condition makes sure concurrent requests can't use the same token twice
+	tx = s.db.Model(&Token{}).
+		Where("id = ? AND used_at IS NULL", token.Id).
+		Update("used_at", now)
+	if tx.Error != nil {
+		return Token{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return Token{}, ErrInvalidToken
+	}
+
+	token.UsedAt = &now
+	return token, nil
+}
+
+// returns the token if it's valid, without using it
+func (s *GormStore) CheckToken(raw string, purpose string) (Token, error) {
+	var token Token
+	tx := s.db.Where("hash = ? AND purpose = ?", util.HashToken(raw), purpose).Limit(1).Find(&token)
+	if tx.Error != nil {
+		return Token{}, tx.Error
+	}
+	if tx.RowsAffected == 0 || token.UsedAt != nil || !token.ExpiresAt.After(time.Now().UTC()) {
+		return Token{}, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+// counts a failed attempt of the token, which is invalidated after too many of them
+func (s *GormStore) FailToken(id int) error {
+	return s.db.Model(&Token{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"),
+		"used_at":  gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ? ELSE used_at END", maxTokenAttempts, time.Now().UTC()),
+	}).Error
+}
+
+// returns when the last token with the given purpose was created for the user
+func (s *GormStore) LastTokenCreatedAt(userId int, purpose string) (time.Time, bool) {
+	var token Token
+	tx := s.db.Where("user_id = ? AND purpose = ?", userId, purpose).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&token)
+
+	if tx.Error != nil || tx.RowsAffected == 0 {
+		return time.Time{}, false
+	}
+
+	return token.CreatedAt, true
+}
+
+func invalidateTokens(tx *gorm.DB, userId int, purpose string, now time.Time) error {
+	return tx.Model(&Token{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userId, purpose).
+		Update("used_at", now).Error
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+	"gorm.io/gorm"
+)
+
+// how many recovery codes a user gets when 2fa is enabled
+const recoveryCodeCount = 10
+
+var ErrInvalidCode = errors.New("invalid code")
+
+// defining a model struct
+// only the hash of the code is stored in the db
+type RecoveryCode struct {
+	Id     int        `gorm:"primaryKey"`
+	UserId int        `gorm:"not null;index"`
+	Hash   string     `gorm:"not null;size:64"`
+	UsedAt *time.Time `gorm:"default:null"`
+}
+
+// defining a TotpSetup for the documentation
+type TotpSetup struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	Uri    string `json:"uri" example:"otpauth://totp/ToDo:johndoe@gmail.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=ToDo"`
+}
+
+// defining a TotpCode for the documentation
+type TotpCode struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// defining a RecoveryCodes for the documentation
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes" example:"k3x9q-2mfpa,7hd2w-qp4zn"`
+}
+
+// defining a TotpDisable for the documentation
+type TotpDisable struct {
+	Password string `json:"password" example:"SuperSecret69"`
+	Code     string `json:"code" example:"123456"`
+}
+
+// defining a LoginTotp for the documentation
+type LoginTotp struct {
+	MfaToken string `json:"mfaToken" example:"qGfTnC0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"`
+	Code     string `json:"code" example:"123456"`
+}
+
+// defining a MfaChallenge for the documentation
+type MfaChallenge struct {
+	MfaRequired bool   `json:"mfaRequired" example:"true"`
+	MfaToken    string `json:"mfaToken" example:"qGfTnC0Vb1uK9f4bHhW2i2xZ3m2xNw6kIjXcP1rYzQk"`
+}
+
+// saves a new secret for the user, which is only used after it's confirmed
+func (s *GormStore) StartTotp(userId int) (string, error) {
+	secret, err := util.GenerateTotpSecret()
+	if err != nil {
+		return "", err
+	}
+
+	tx := s.db.Model(&User{}).Where("id = ? AND totp_enabled = ?", userId, false).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	})
+	return secret, tx.Error
+}
+
+// enables 2fa if the code is valid, and returns the new recovery codes
+func (s *GormStore) EnableTotp(user User, code string) ([]string, error) {
+	step, ok := util.ValidateTotp(user.TotpSecret, code, time.Now())
+	if user.TotpSecret == "" || !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes := []string{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", user.Id).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// the old codes are replaced
+		if err := tx.Where("user_id = ?", user.Id).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for i := 0; i < recoveryCodeCount; i++ {
+			code, err := generateRecoveryCode()
+			if err != nil {
+				return err
+			}
+
+			recovery := RecoveryCode{UserId: user.Id, Hash: util.HashToken(code)}
+			if err := tx.Create(&recovery).Error; err != nil {
+				return err
+			}
+
+			codes = append(codes, code)
+		}
+
+		return nil
+	})
+
+	return codes, err
+}
+
+// turns off 2fa and deletes the recovery codes of the user
+func (s *GormStore) DisableTotp(userId int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userId).Delete(&RecoveryCode{}).Error
+	})
+}
+
+// checks the code of the authenticator app or an unused recovery code
+// both of them can only be used once
+func (s *GormStore) VerifySecondFactor(user User, code string) (bool, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	if step, ok := util.ValidateTotp(user.TotpSecret, code, time.Now()); ok {
+		// the step condition makes sure a code can't be used twice
+		tx := s.db.Model(&User{}).
+			Where("id = ? AND totp_last_step < ?", user.Id, step).
+			Update("totp_last_step", step)
+		return tx.RowsAffected > 0, tx.Error
+	}
+
+	tx := s.db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND hash = ? AND used_at IS NULL", user.Id, util.HashToken(code)).
+		Update("used_at", time.Now().UTC())
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// generates a code like k3x9q-2mfpa
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 7)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(bytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
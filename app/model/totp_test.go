@@ -0,0 +1,142 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+)
+
+// starts and enables 2fa for the user, and returns the user with the secret and the recovery codes
+func enableTestTotp(t *testing.T, s *GormStore, user User) (User, []string) {
+	t.Helper()
+
+	secret, err := s.StartTotp(user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.TotpSecret = secret
+
+	code, err := util.TotpCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := s.EnableTotp(user, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err = s.GetUserById(user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user, codes
+}
+
+func TestEnableTotp(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Totp User")
+
+	secret, err := s.StartTotp(user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.TotpSecret = secret
+
+	if _, err := s.EnableTotp(user, "000000"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("err = %v, want ErrInvalidCode", err)
+	}
+
+	user, codes := enableTestTotp(t, s, user)
+	if !user.TotpEnabled || user.TotpSecret == "" || len(codes) != recoveryCodeCount {
+		t.Fatalf("user = %+v with %d codes, want 2fa enabled with %d codes", user, len(codes), recoveryCodeCount)
+	}
+
+	// only the hashes of the codes are stored
+	if n := countRows(t, s, &RecoveryCode{}, "user_id = ? AND hash = ?", user.Id, codes[0]); n != 0 {
+		t.Error("the recovery code is stored in plain text")
+	}
+
+	// the secret can't be replaced while 2fa is enabled
+	if _, err := s.StartTotp(user.Id); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.GetUserById(user.Id); again.TotpSecret != user.TotpSecret {
+		t.Error("the secret was replaced while 2fa is enabled")
+	}
+
+	// disabling deletes the recovery codes
+	if err := s.DisableTotp(user.Id); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, s, &RecoveryCode{}, "user_id = ?", user.Id); n != 0 {
+		t.Errorf("%d recovery codes are left", n)
+	}
+}
+
+func TestTotpCodesCanOnlyBeUsedOnce(t *testing.T) {
+	s := newTestStore(t)
+	user, _ := enableTestTotp(t, s, createTestUser(t, s, "Totp User"))
+
+	// the code used to enable 2fa is spent
+	current, _ := util.TotpCode(user.TotpSecret, time.Now())
+	if ok, err := s.VerifySecondFactor(user, current); err != nil || ok {
+		t.Errorf("the code used for enabling was accepted again: %v, %v", ok, err)
+	}
+
+	// the next code is accepted once, because of the clock drift
+	next, _ := util.TotpCode(user.TotpSecret, time.Now().Add(30*time.Second))
+	if ok, err := s.VerifySecondFactor(user, next); err != nil || !ok {
+		t.Fatalf("the next code was rejected: %v, %v", ok, err)
+	}
+	if ok, _ := s.VerifySecondFactor(user, next); ok {
+		t.Error("the next code was accepted twice")
+	}
+
+	// the older codes are rejected after a newer one was used
+	previous, _ := util.TotpCode(user.TotpSecret, time.Now().Add(-30*time.Second))
+	if ok, _ := s.VerifySecondFactor(user, previous); ok {
+		t.Error("an older code was accepted after a newer one")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	s := newTestStore(t)
+	user, codes := enableTestTotp(t, s, createTestUser(t, s, "Totp User"))
+	other, otherCodes := enableTestTotp(t, s, createTestUser(t, s, "Other User"))
+
+	// the codes are accepted with spaces and in upper case too
+	if ok, err := s.VerifySecondFactor(user, "  "+strings.ToUpper(codes[0])+" "); err != nil || !ok {
+		t.Fatalf("the recovery code was rejected: %v, %v", ok, err)
+	}
+	if ok, _ := s.VerifySecondFactor(user, codes[0]); ok {
+		t.Error("the recovery code was accepted twice")
+	}
+	if n := countRows(t, s, &RecoveryCode{}, "user_id = ? AND used_at IS NOT NULL", user.Id); n != 1 {
+		t.Errorf("%d used recovery codes, want 1", n)
+	}
+
+	// the other codes still work, but not the ones of other users
+	if ok, _ := s.VerifySecondFactor(user, codes[1]); !ok {
+		t.Error("another recovery code was rejected")
+	}
+	if ok, _ := s.VerifySecondFactor(user, otherCodes[0]); ok {
+		t.Error("the recovery code of another user was accepted")
+	}
+	if ok, _ := s.VerifySecondFactor(other, otherCodes[0]); !ok {
+		t.Error("the recovery code of the other user was rejected")
+	}
+
+	// enabling 2fa again replaces the codes
+	if err := s.DisableTotp(user.Id); err != nil {
+		t.Fatal(err)
+	}
+	user, _ = enableTestTotp(t, s, user)
+	if ok, _ := s.VerifySecondFactor(user, codes[2]); ok {
+		t.Error("an old recovery code was accepted after enabling 2fa again")
+	}
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the maximum number of tasks that can be moved or copied at once
+const maxTransferTasks = 100
+
+// defining a TransferTasks for moving and copying tasks between lists
+type TransferTasks struct {
+	TaskIds       []int `json:"taskIds" example:"1,2,3"`
+	ListId        int   `json:"listId" example:"2"`
+	RegenerateUrl bool  `json:"regenerateUrl" example:"false"`
+}
+
+func (transfer TransferTasks) Validate() (bool, string) {
+	// if there are no tasks
+	if len(transfer.TaskIds) == 0 {
+		return false, "Please specify at least one task."
+	}
+
+	// if there are too many tasks
+	if len(transfer.TaskIds) > maxTransferTasks {
+		return false, "Maximum 100 tasks can be transferred at once."
+	}
+
+	// if a task is specified more than once
+	seen := map[int]bool{}
+	for _, id := range transfer.TaskIds {
+		if seen[id] {
+			return false, "Every task can only be specified once."
+		}
+		seen[id] = true
+	}
+
+	return true, ""
+}
+
+func (s *GormStore) MoveTasks(ids []int, listId int, regenerateUrl bool, userId int) ([]Task, error) {
+	// the moved tasks go to the top of the list in the given order
+	first := firstTaskPosition(s.db, listId)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			changes := map[string]interface{}{
+				"list_id":  listId,
+				"position": first - float64(len(ids)-1-i)*positionGap,
+			}
+
+			// giving the task a new url if requested
+			if regenerateUrl {
+				var task Task
+				if err := tx.Where("id = ?", id).First(&task).Error; err != nil {
+					return err
+				}
+
+				changes["url"] = createTaskUrl(task.Title)
+			}
+
+			if err := tx.Model(&Task{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+
+		// the users who can't access the new list lose their tags on the tasks
+		return dropInaccessibleTags(tx, ids, listId)
+	})
+	if err != nil {
+		return []Task{}, err
+	}
+
+	return s.getTransferredTasks(ids, userId)
+}
+
+func (s *GormStore) CopyTasks(ids []int, listId int, userId int) ([]Task, error) {
+	// the copies go to the top of the list in the given order
+	first := firstTaskPosition(s.db, listId)
+	now := time.Now()
+	copies := []int{}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			var task Task
+			if err := tx.Where("id = ?", id).First(&task).Error; err != nil {
+				return err
+			}
+
+			// the copy is a new task created by the user
+			original := task.Id
+			task.Id = 0
+			task.ListId = listId
+			task.CreatedById = &userId
+			task.Url = createTaskUrl(task.Title)
+			task.CreatedAt = now
+			task.Position = first - float64(len(ids)-1-i)*positionGap
+
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+
+			// copying the checklist and the tags of the user
+			if err := copyItems(tx, original, task.Id); err != nil {
+				return err
+			}
+
+			if err := copyTags(tx, original, task.Id, userId); err != nil {
+				return err
+			}
+
+			copies = append(copies, task.Id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return []Task{}, err
+	}
+
+	return s.getTransferredTasks(copies, userId)
+}
+
+func (s *GormStore) getTransferredTasks(ids []int, userId int) ([]Task, error) {
+	var tasks []Task
+
+	// getting the tasks from the db in their new order with the tags of the user
+	tx := preloadTags(s.db, userId).Where("id IN ?", ids).Order(orderBy(taskSorts["position"])).Find(&tasks)
+	if tx.Error != nil {
+		return []Task{}, tx.Error
+	}
+
+	// counting the checklist items of the tasks
+	err := loadProgress(s.db, tasks)
+	return tasks, err
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// tags the task with a new tag of the user, and returns the tag
+func createTestTag(t *testing.T, s *GormStore, taskId int, ownerId int, name string) Tag {
+	t.Helper()
+
+	tag, err := s.CreateTag(Tag{OwnerId: ownerId, Name: name, Color: "#ef4444"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetTaskTags(taskId, ownerId, []Tag{tag}); err != nil {
+		t.Fatal(err)
+	}
+
+	return tag
+}
+
+// returns the ids of every tag of the task, whoever owns them
+func linkedTagIds(t *testing.T, s *GormStore, taskId int) []int {
+	t.Helper()
+
+	var ids []int
+	if err := s.db.Model(&TaskTag{}).Where("task_id = ?", taskId).Pluck("tag_id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+func TestTransferredTags(t *testing.T) {
+	s := newTestStore(t)
+	owner := createTestUser(t, s, "List Owner")
+	editor := createTestUser(t, s, "List Editor")
+	shared := createTestList(t, s, owner.Id, "Shared")
+	private := createTestList(t, s, owner.Id, "Private")
+	other := createTestList(t, s, editor.Id, "Other")
+	if _, err := s.CreateMember(Member{ListId: shared.Id, UserId: editor.Id, Role: RoleEditor}); err != nil {
+		t.Fatal(err)
+	}
+
+	task := createTestTask(t, s, shared.Id, owner.Id, "Tagged")
+	ownerTag := createTestTag(t, s, task.Id, owner.Id, "urgent")
+	editorTag := createTestTag(t, s, task.Id, editor.Id, "later")
+
+	// the copy only gets the tags of the user who copied it
+	copies, err := s.CopyTasks([]int{task.Id}, shared.Id, owner.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := linkedTagIds(t, s, copies[0].Id); !reflect.DeepEqual(ids, []int{ownerTag.Id}) {
+		t.Errorf("tags of the copy = %v, want only the tag of the owner %d", ids, ownerTag.Id)
+	}
+	if ids := linkedTagIds(t, s, task.Id); !reflect.DeepEqual(ids, []int{ownerTag.Id, editorTag.Id}) {
+		t.Errorf("tags of the original = %v", ids)
+	}
+
+	// the tags are kept if their owners can still access the task
+	if _, err := s.MoveTasks([]int{task.Id}, shared.Id, false, owner.Id); err != nil {
+		t.Fatal(err)
+	}
+	if ids := linkedTagIds(t, s, task.Id); !reflect.DeepEqual(ids, []int{ownerTag.Id, editorTag.Id}) {
+		t.Errorf("tags after moving in the list = %v", ids)
+	}
+
+	// the editor can't access the private list of the owner, so their tag is dropped
+	if _, err := s.MoveTasks([]int{task.Id}, private.Id, false, owner.Id); err != nil {
+		t.Fatal(err)
+	}
+	if ids := linkedTagIds(t, s, task.Id); !reflect.DeepEqual(ids, []int{ownerTag.Id}) {
+		t.Errorf("tags after moving to the private list = %v, want only %d", ids, ownerTag.Id)
+	}
+
+	// and the owner of the list isn't a member of the other list
+	moved := createTestTask(t, s, shared.Id, editor.Id, "Moved by the editor")
+	createTestTag(t, s, moved.Id, owner.Id, "mine")
+	editorOwn := createTestTag(t, s, moved.Id, editor.Id, "theirs")
+	if _, err := s.MoveTasks([]int{moved.Id}, other.Id, false, editor.Id); err != nil {
+		t.Fatal(err)
+	}
+	if ids := linkedTagIds(t, s, moved.Id); !reflect.DeepEqual(ids, []int{editorOwn.Id}) {
+		t.Errorf("tags after moving to the list of the editor = %v, want only %d", ids, editorOwn.Id)
+	}
+}
+
+func TestNextOccurrenceTags(t *testing.T) {
+	s := newTestStore(t)
+	owner := createTestUser(t, s, "List Owner")
+	editor := createTestUser(t, s, "List Editor")
+	list := createTestList(t, s, owner.Id, "Chores")
+	if _, err := s.CreateMember(Member{ListId: list.Id, UserId: editor.Id, Role: RoleEditor}); err != nil {
+		t.Fatal(err)
+	}
+
+	due := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	task := createTestTask(t, s, list.Id, owner.Id, "Water the plants")
+	task.DueDate = &due
+	task.Recurrence = "FREQ=DAILY"
+	if _, err := s.EditTask(task); err != nil {
+		t.Fatal(err)
+	}
+	createTestTag(t, s, task.Id, owner.Id, "home")
+	editorTag := createTestTag(t, s, task.Id, editor.Id, "plants")
+
+	// the editor completes the task, so the next occurrence only gets their tags
+	if _, err := s.ChangeIsDone(task.Id, false, editor.Id, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+
+	var next Task
+	if err := s.db.Where("list_id = ? AND id <> ?", list.Id, task.Id).First(&next).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ids := linkedTagIds(t, s, next.Id); !reflect.DeepEqual(ids, []int{editorTag.Id}) {
+		t.Errorf("tags of the next occurrence = %v, want only %d", ids, editorTag.Id)
+	}
+}
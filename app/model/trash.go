@@ -0,0 +1,186 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TrashList = "list"
+	TrashTask = "task"
+)
+
+// how long the deleted tasks and lists are kept in the trash
+var TrashRetention = 30 * 24 * time.Hour
+
+// how often the expired tasks and lists are deleted permanently
+const trashPurgeInterval = time.Hour
+
+func SetupTrash() error {
+	// the retention can be changed with the environment variables, it's 30 days by default
+	value := os.Getenv("TRASH_RETENTION_DAYS")
+	if value == "" {
+		return nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return fmt.Errorf("invalid TRASH_RETENTION_DAYS: %s, it has to be a positive number of days", value)
+	}
+
+	TrashRetention = time.Duration(days) * 24 * time.Hour
+	return nil
+}
+
+// a deleted task or list in the trash of the user
+type TrashItem struct {
+	Type      string    `json:"type" example:"task"`
+	Id        int       `json:"id" example:"1"`
+	ListId    int       `json:"listId" example:"1"`
+	Title     string    `json:"title" example:"Buy milk"`
+	DeletedAt time.Time `json:"deletedAt" example:"2022-07-21T08:12:45Z"`
+	PurgeAt   time.Time `json:"purgeAt" example:"2022-08-20T08:12:45Z"`
+}
+
+// returns the lists the user deleted, and the tasks deleted from the lists the user can edit
+// the tasks deleted together with their list are restored with the list, so they are not listed
+func (s *GormStore) GetTrash(userId int) ([]TrashItem, error) {
+	var lists []List
+	tx := s.db.Unscoped().Where("owner_id = ? AND deleted_at IS NOT NULL", userId).Find(&lists)
+	if tx.Error != nil {
+		return []TrashItem{}, tx.Error
+	}
+
+	var tasks []Task
+	tx = s.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_with_list = ?", false).
+		Where("list_id IN (?)", editableListIds(s.db, userId)).
+		Find(&tasks)
+	if tx.Error != nil {
+		return []TrashItem{}, tx.Error
+	}
+
+	items := []TrashItem{}
+	for _, list := range lists {
+		items = append(items, trashItem(TrashList, list.Id, list.Id, list.Name, list.DeletedAt.Time))
+	}
+	for _, task := range tasks {
+		items = append(items, trashItem(TrashTask, task.Id, task.ListId, task.Title, task.DeletedAt.Time))
+	}
+
+	// the most recently deleted ones come first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	return items, nil
+}
+
+func trashItem(kind string, id int, listId int, title string, deletedAt time.Time) TrashItem {
+	return TrashItem{
+		Type:      kind,
+		Id:        id,
+		ListId:    listId,
+		Title:     title,
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.Add(TrashRetention),
+	}
+}
+
+// returns a subquery selecting the ids of the lists the user can edit
+func editableListIds(db *gorm.DB, userId int) *gorm.DB {
+	shared := db.Model(&Member{}).Select("list_id").Where("user_id = ? AND role = ?", userId, RoleEditor)
+
+	return db.Model(&List{}).Select("id").Where("owner_id = ?", userId).Or("id IN (?)", shared)
+}
+
+// returns the list whether it's in the trash or not, its DeletedAt tells which
+func (s *GormStore) GetListWithTrashed(id int) (List, error) {
+	var list List
+	tx := s.db.Unscoped().Where("id = ?", id).First(&list)
+	return list, tx.Error
+}
+
+// returns the task whether it's in the trash or not, its DeletedAt tells which
+func (s *GormStore) GetTaskWithTrashed(id int) (Task, error) {
+	var task Task
+	tx := s.db.Unscoped().Where("id = ?", id).First(&task)
+	return task, tx.Error
+}
+
+func (s *GormStore) RestoreList(id int) (List, error) {
+	// restoring the list and the tasks deleted together with it in one transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&Task{}).
+			Where("list_id = ? AND deleted_with_list = ?", id, true).
+			Updates(map[string]interface{}{"deleted_at": nil, "deleted_with_list": false}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&List{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return List{}, err
+	}
+
+	list, _ := s.ListExists(id)
+	return list, nil
+}
+
+func (s *GormStore) RestoreTask(id int, userId int) (Task, error) {
+	tx := s.db.Unscoped().Model(&Task{}).Where("id = ?", id).Update("deleted_at", nil)
+	if tx.Error != nil {
+		return Task{}, tx.Error
+	}
+
+	// getting the restored task with the tags of the user and its checklist items
+	var task Task
+	tx = preloadTags(s.db, userId).First(&task, id)
+	if tx.Error != nil {
+		return Task{}, tx.Error
+	}
+
+	tasks := []Task{task}
+	err := loadProgress(s.db, tasks)
+	return tasks[0], err
+}
+
+func (s *GormStore) PurgeList(id int) error {
+	// deleting the list permanently, the foreign keys delete its tasks and members
+	tx := s.db.Unscoped().Delete(&List{}, id)
+	return tx.Error
+}
+
+func (s *GormStore) PurgeTask(id int) error {
+	// deleting the task permanently, the foreign keys delete its items and its links to the tags
+	tx := s.db.Unscoped().Delete(&Task{}, id)
+	return tx.Error
+}
+
+// permanently deletes the tasks and the lists deleted before the given time
+func (s *GormStore) PurgeTrash(before time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at < ?", before).Delete(&List{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("deleted_at < ?", before).Delete(&Task{}).Error
+	})
+}
+
+// empties the expired part of the trash every hour, it's started in the background by main
+func (s *GormStore) PurgeTrashPeriodically() {
+	for {
+		if err := s.PurgeTrash(time.Now().UTC().Add(-TrashRetention)); err != nil {
+			fmt.Println("Failed to empty the trash: " + err.Error())
+		}
+
+		time.Sleep(trashPurgeInterval)
+	}
+}
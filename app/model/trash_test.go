@@ -0,0 +1,201 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func trashIds(t *testing.T, s *GormStore, userId int) map[string][]int {
+	t.Helper()
+
+	items, err := s.GetTrash(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string][]int{}
+	for _, item := range items {
+		ids[item.Type] = append(ids[item.Type], item.Id)
+	}
+
+	return ids
+}
+
+func TestDeleteAndRestoreList(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Trash User")
+	list := createTestList(t, s, user.Id, "Trashed")
+
+	earlier := createTestTask(t, s, list.Id, user.Id, "Deleted earlier")
+	task := createTestTask(t, s, list.Id, user.Id, "Deleted with the list")
+
+	if err := s.DeleteTask(earlier.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteList(list.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// the list and its tasks are hidden
+	if _, ok := s.ListExists(list.Id); ok {
+		t.Error("the deleted list still exists")
+	}
+	if _, ok := s.TaskExists(task.Id); ok {
+		t.Error("the task of the deleted list still exists")
+	}
+
+	// the tasks of a deleted list can only be restored with it, so only the list is in the trash
+	ids := trashIds(t, s, user.Id)
+	if len(ids[TrashList]) != 1 || ids[TrashList][0] != list.Id || len(ids[TrashTask]) != 0 {
+		t.Errorf("trash = %v, want only the list %d", ids, list.Id)
+	}
+
+	if _, err := s.RestoreList(list.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.TaskExists(task.Id); !ok {
+		t.Error("the task deleted with the list was not restored")
+	}
+	if _, ok := s.TaskExists(earlier.Id); ok {
+		t.Error("the task deleted before the list was restored")
+	}
+
+	// the task deleted before the list is in the trash again
+	ids = trashIds(t, s, user.Id)
+	if len(ids[TrashList]) != 0 || len(ids[TrashTask]) != 1 || ids[TrashTask][0] != earlier.Id {
+		t.Errorf("trash after restoring = %v, want only the task %d", ids, earlier.Id)
+	}
+}
+
+func TestTrashOfSharedLists(t *testing.T) {
+	s := newTestStore(t)
+	owner := createTestUser(t, s, "List Owner")
+	editor := createTestUser(t, s, "List Editor")
+	viewer := createTestUser(t, s, "List Viewer")
+
+	list := createTestList(t, s, owner.Id, "Shared")
+	for _, member := range []Member{{ListId: list.Id, UserId: editor.Id, Role: RoleEditor}, {ListId: list.Id, UserId: viewer.Id, Role: RoleViewer}} {
+		if _, err := s.CreateMember(member); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task := createTestTask(t, s, list.Id, owner.Id, "Shared task")
+	if err := s.DeleteTask(task.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// the users who can edit the list see its deleted tasks
+	for _, user := range []User{owner, editor} {
+		if ids := trashIds(t, s, user.Id); len(ids[TrashTask]) != 1 {
+			t.Errorf("trash of %s = %v, want the deleted task", user.Name, ids)
+		}
+	}
+	if ids := trashIds(t, s, viewer.Id); len(ids) != 0 {
+		t.Errorf("trash of the viewer = %v, want it empty", ids)
+	}
+
+	// the deleted lists are only in the trash of their owner
+	if err := s.DeleteList(list.Id); err != nil {
+		t.Fatal(err)
+	}
+	if ids := trashIds(t, s, editor.Id); len(ids) != 0 {
+		t.Errorf("trash of the editor = %v, want it empty", ids)
+	}
+}
+
+func TestRestoreTask(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Trash User")
+	list := createTestList(t, s, user.Id, "Tasks")
+	task := createTestTask(t, s, list.Id, user.Id, "Restored")
+
+	if _, err := s.CreateItem(Item{TaskId: task.Id, Title: "Step"}); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := s.CreateTag(Tag{OwnerId: user.Id, Name: "home", Color: "#ffffff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTaskTags(task.Id, user.Id, []Tag{tag}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteTask(task.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := s.RestoreTask(task.Id, user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the items and the tags are kept in the trash
+	if restored.Progress.Total != 1 || len(restored.Tags) != 1 || restored.Tags[0].Id != tag.Id {
+		t.Errorf("restored task = %+v, want it with its item and tag", restored)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Trash User")
+
+	oldList := createTestList(t, s, user.Id, "Old list")
+	newList := createTestList(t, s, user.Id, "New list")
+	kept := createTestList(t, s, user.Id, "Kept")
+
+	oldListTask := createTestTask(t, s, oldList.Id, user.Id, "In the old list")
+	oldTask := createTestTask(t, s, kept.Id, user.Id, "Old task")
+	newTask := createTestTask(t, s, kept.Id, user.Id, "New task")
+	keptTask := createTestTask(t, s, kept.Id, user.Id, "Kept task")
+
+	if _, err := s.CreateItem(Item{TaskId: oldTask.Id, Title: "Step"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int{oldList.Id, newList.Id} {
+		if err := s.DeleteList(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []int{oldTask.Id, newTask.Id} {
+		if err := s.DeleteTask(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the old ones have been in the trash for longer than the retention,
+	// the task of the old list is only purged with the list
+	now := time.Now().UTC()
+	expired := now.Add(-TrashRetention - time.Hour)
+	s.db.Unscoped().Model(&List{}).Where("id = ?", oldList.Id).Update("deleted_at", expired)
+	s.db.Unscoped().Model(&Task{}).Where("id = ?", oldTask.Id).Update("deleted_at", expired)
+
+	if err := s.PurgeTrash(now.Add(-TrashRetention)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range []struct {
+		name  string
+		model interface{}
+		id    int
+		want  int64
+	}{
+		{"the expired list", &List{}, oldList.Id, 0},
+		{"the task of the expired list", &Task{}, oldListTask.Id, 0},
+		{"the expired task", &Task{}, oldTask.Id, 0},
+		{"the recently deleted list", &List{}, newList.Id, 1},
+		{"the recently deleted task", &Task{}, newTask.Id, 1},
+		{"the list that is not deleted", &List{}, kept.Id, 1},
+		{"the task that is not deleted", &Task{}, keptTask.Id, 1},
+	} {
+		if n := countRows(t, s, row.model, "id = ?", row.id); n != row.want {
+			t.Errorf("%s: %d rows, want %d", row.name, n, row.want)
+		}
+	}
+
+	if n := countRows(t, s, &Item{}, "task_id = ?", oldTask.Id); n != 0 {
+		t.Errorf("%d items of the purged task are left", n)
+	}
+}
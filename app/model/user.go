@@ -1,12 +1,16 @@
 package model
 
 import (
+	"errors"
 	"os"
 	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // defining a model struct
@@ -16,40 +20,84 @@ type User struct {
 	Email     string `json:"email" gorm:"not null;unique" example:"johndoe@gmail.com"`
 	Password  string `json:"password,omitempty" gorm:"not null;column:password" example:"secret"`
 	IsEnabled bool   `json:"isEnabled" gorm:"not null;column:is_enabled" example:"true"`
+	AvatarUrl string `json:"avatarURL" gorm:"column:avatar_url" example:"/assets/images/hfhu39Hfeu.png"`
+
+	// the new email address of the user until it's verified
+	PendingEmail string `json:"pendingEmail,omitempty" gorm:"column:pending_email" example:"john@doe.com"`
+
+	// the secret of the authenticator app, and the time step of the last used code
+	TotpEnabled  bool   `json:"totpEnabled" gorm:"not null;default:false" example:"false"`
+	TotpSecret   string `json:"-" gorm:"size:64"`
+	TotpLastStep int64  `json:"-" gorm:"not null;default:0"`
 }
 
+var (
+	ErrTokenRevoked = errors.New("the token has been revoked")
+	ErrEmailTaken   = errors.New("the email is already registered")
+)
+
 // defining a LoginUser for the documentation
 type LoginUser struct {
 	Email    string `json:"email" example:"johndoe@gmail.com"`
 	Password string `json:"password" example:"SuperSecret69"`
 }
 
+// defining an EditProfile for the documentation
+type EditProfile struct {
+	Name      string `json:"name" example:"John Doe"`
+	Email     string `json:"email" example:"johndoe@gmail.com"`
+	AvatarUrl string `json:"avatarURL" example:"/assets/images/hfhu39Hfeu.png"`
+}
+
+// defining a ChangePassword for the documentation
+type ChangePassword struct {
+	CurrentPassword string `json:"currentPassword" example:"SuperSecret69"`
+	NewPassword     string `json:"newPassword" example:"EvenMoreSecret420"`
+}
+
 func (user User) Validate() (bool, string) {
+	// validating the name
+	if ok, msg := ValidateName(user.Name); !ok {
+		return false, msg
+	}
+
+	// validating the email
+	if ok, msg := ValidateEmail(user.Email); !ok {
+		return false, msg
+	}
+
+	// if the user is valid
+	return true, ""
+}
+
+func ValidateName(name string) (bool, string) {
 	// if the length of the username is less than 6 characters
-	if len(user.Name) < 6 {
+	if len(name) < 6 {
 		return false, "Your name has to be at least 6 characters long."
 	}
 
 	// if the length of the username is more than 64 characters
-	if len(user.Name) > 64 {
+	if len(name) > 64 {
 		return false, "The length of your name should be maximum of 64 characters long."
 	}
 
+	return true, ""
+}
+
+func ValidateEmail(email string) (bool, string) {
 	// creating the regexp for the email validation
 	r, _ := regexp.Compile("^[a-zA-Z0-9+_.-]+@[a-zA-Z0-9.-]+$")
 
-	// validating the email
-	if !r.MatchString(user.Email) {
+	if !r.MatchString(email) {
 		return false, "Please provide a valid email address!"
 	}
 
-	// if the user is valid
 	return true, ""
 }
 
-func ExistsByEmail(email string) bool {
+func (s *GormStore) ExistsByEmail(email string) bool {
 	// getting the user from the db
-	user, err := GetUserByEmail(email)
+	user, err := s.GetUserByEmail(email)
 
 	// if there's an error, the user does not exist
 	if err != nil {
@@ -65,47 +113,142 @@ func ExistsByEmail(email string) bool {
 	return true
 }
 
-func Register(user User) (User, error) {
+func (s *GormStore) Register(user User) (User, error) {
 	// encrypting the password with bcrypt
 	encrypted, _ := bcrypt.GenerateFromPassword([]byte(user.Password), 14)
 
 	// overriding the values
-	user.IsEnabled = true
+	// the user is enabled after the email address is verified
+	user.IsEnabled = false
+	user.AvatarUrl = ""
+	user.PendingEmail = ""
+	user.TotpEnabled = false
 	user.Password = string(encrypted)
 
 	// creating the user
-	tx := DB.Create(&user)
+	tx := s.db.Create(&user)
 	return user, tx.Error
 }
 
-// if the specified id is an int
-func GetUserById(id int) (User, error) {
-	// getting the user form the db by id
-	var user User
-	tx := DB.Where("id = ?", id).First(&user)
-	return user, tx.Error
+func ValidatePassword(password string) (bool, string) {
+	// if the length of the password is less than 8 characters
+	if len(password) < 8 {
+		return false, "Your password has to be at least 8 characters long."
+	}
+
+	// bcrypt only uses the first 72 bytes of the password
+	if len(password) > 72 {
+		return false, "The length of your password should be maximum of 72 characters long."
+	}
+
+	return true, ""
 }
 
-// if the specified id is a string
-func GetUserByStringId(id string) (User, error) {
+// sets the password of the user and revokes every session, except the one with the given id
+func (s *GormStore) SetPassword(id int, password string, keepSessionId int) error {
+	// encrypting the password with bcrypt
+	encrypted, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", id).Update("password", string(encrypted)).Error
+		if err != nil {
+			return err
+		}
+
+		return revokeSessions(tx, id, keepSessionId)
+	})
+}
+
+// sets the password of the user after a password reset
+// the account may have been taken over, so every session and personal access token is revoked
+func (s *GormStore) ResetPassword(id int, password string) error {
+	encrypted, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", id).Update("password", string(encrypted)).Error
+		if err != nil {
+			return err
+		}
+
+		if err := revokeSessions(tx, id, 0); err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", id).Delete(&AccessToken{}).Error
+	})
+}
+
+// saves the name, the avatar and the pending email of the user
+func (s *GormStore) EditUser(user User) error {
+	tx := s.db.Model(&User{}).Where("id = ?", user.Id).Updates(map[string]interface{}{
+		"name":          user.Name,
+		"avatar_url":    user.AvatarUrl,
+		"pending_email": user.PendingEmail,
+	})
+	return tx.Error
+}
+
+// replaces the email of the user with the pending one
+func (s *GormStore) ConfirmEmail(id int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Where("id = ?", id).First(&user).Error; err != nil {
+			return err
+		}
+
+		// if the email change has been cancelled since the token was sent
+		if user.PendingEmail == "" {
+			return ErrInvalidToken
+		}
+
+		// if someone else registered with the email in the meantime
+		var count int64
+		tx.Model(&User{}).Where("email = ? AND id <> ?", user.PendingEmail, id).Count(&count)
+		if count > 0 {
+			return ErrEmailTaken
+		}
+
+		return tx.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"email":         user.PendingEmail,
+			"pending_email": "",
+			"is_enabled":    true,
+		}).Error
+	})
+}
+
+func (s *GormStore) EnableUser(id int) error {
+	// enabling the user with the specified id
+	tx := s.db.Model(&User{}).Where("id = ?", id).Update("is_enabled", true)
+	return tx.Error
+}
+
+// if the specified id is an int
+func (s *GormStore) GetUserById(id int) (User, error) {
 	// getting the user form the db by id
 	var user User
-	tx := DB.Where("id = ?", id).First(&user)
+	tx := s.db.Where("id = ?", id).First(&user)
 	return user, tx.Error
 }
 
-func GetUserByEmail(email string) (User, error) {
+func (s *GormStore) GetUserByEmail(email string) (User, error) {
 	// getting the user form the db by the specified email
 	var user User
-	tx := DB.Where("email = ?", email).First(&user)
+	tx := s.db.Where("email = ?", email).First(&user)
 	return user, tx.Error
 }
 
-func GetLoggedInUser(c gin.Context) (User, error) {
+// returns the session of the request, the user of the session can be loaded with its UserId
+func (s *GormStore) GetLoggedInSession(c *gin.Context) (Session, error) {
 	// getting the cookie from the request
 	cookie, err := c.Request.Cookie("jwt")
 	if err != nil {
-		return User{}, err
+		return Session{}, err
 	}
 
 	// parsing the token from the cookie
@@ -117,26 +260,29 @@ func(t *jwt.Token) (interface{}, error) {
 		},
 	)
 	if err != nil {
-		return User{}, err
+		return Session{}, err
 	}
 
 	// getting the claims from the token
+	// the id of the token is the id of the session
 	claims := token.Claims.(*jwt.StandardClaims)
 
-	// getting the user from the db
-	user, err := GetUserByStringId(claims.Issuer)
+	sessionId, err := strconv.Atoi(claims.Id)
 	if err != nil {
-		return User{}, err
+		return Session{}, ErrTokenRevoked
 	}
 
-	// if the user is logged in
-	return user, nil
-}
+	// the token is only valid while its session is active
+	session, err := s.GetSessionById(sessionId)
+	if err != nil {
+		return Session{}, err
+	}
+	if !session.IsActive(time.Now().UTC()) || strconv.Itoa(session.UserId) != claims.Issuer {
+		return Session{}, ErrTokenRevoked
+	}
 
-func IsLoggedIn(c gin.Context) bool {
-	// getting the logged in user
-	_, err := GetLoggedInUser(c)
+	s.TouchSession(session, c.ClientIP())
 
-	// if the error is nil, the user is logged in
-	return err == nil
+	// if the user is logged in
+	return session, nil
 }
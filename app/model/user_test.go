@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestResetPassword(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Reset User")
+	other := createTestUser(t, s, "Other User")
+
+	if _, _, err := s.CreateSession(user.Id, "test", "127.0.0.1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.CreateAccessToken(user.Id, NewAccessToken{Name: "Backup script", Scopes: []string{ScopeRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := s.CreateAccessToken(other.Id, NewAccessToken{Name: "Backup script", Scopes: []string{ScopeRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ResetPassword(user.Id, "EvenMoreSecret420"); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, _ := s.GetUserById(user.Id)
+	if bcrypt.CompareHashAndPassword([]byte(saved.Password), []byte("EvenMoreSecret420")) != nil {
+		t.Error("the new password isn't saved")
+	}
+
+	// every session and access token of the user is revoked, but not the ones of the others
+	if n := countRows(t, s, &Session{}, "user_id = ? AND revoked_at IS NULL", user.Id); n != 0 {
+		t.Errorf("%d sessions are left", n)
+	}
+	if _, err := s.AuthenticateAccessToken(token.Token); err != ErrInvalidAccessToken {
+		t.Errorf("err = %v, want ErrInvalidAccessToken", err)
+	}
+	if _, err := s.AuthenticateAccessToken(otherToken.Token); err != nil {
+		t.Errorf("the token of another user was revoked: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/golang-jwt/jwt"
+)
+
+var ErrInvalidIdToken = errors.New("invalid id token")
+
+// the values of a login, which have to be kept until the callback
+type Flow struct {
+	Provider string
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// the claims of the id token that are used
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// creates the random values of a new login
+func NewFlow(provider string) (Flow, error) {
+	flow := Flow{Provider: provider}
+
+	for _, value := range []*string{&flow.State, &flow.Nonce, &flow.Verifier} {
+		token, err := util.GenerateToken()
+		if err != nil {
+			return Flow{}, err
+		}
+
+		*value = token
+	}
+
+	return flow, nil
+}
+
+// returns the url of the login page of the provider
+// the code challenge is the S256 hash of the verifier (PKCE)
+func (p *Provider) AuthUrl(flow Flow) (string, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.ClientId)
+	query.Set("redirect_uri", p.RedirectUrl)
+	query.Set("scope", strings.Join(p.Scopes, " "))
+	query.Set("state", flow.State)
+	query.Set("nonce", flow.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// exchanges the authorization code for the tokens and returns the id token
+func (p *Provider) Exchange(code string, verifier string) (string, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectUrl)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequest(http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.ClientId), url.QueryEscape(p.ClientSecret))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	if res.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("token request failed: %s %s", body.Error, body.ErrorDescription)
+	}
+
+	if body.IdToken == "" {
+		return "", errors.New("the token response has no id token")
+	}
+
+	return body.IdToken, nil
+}
+
+// validates the signature and the claims of the id token
+func (p *Provider) Verify(idToken string, nonce string) (Claims, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return Claims{}, err
+	}
+
+	token, err := jwt.Parse(idToken, func(t *jwt.Token) (interface{}, error) {
+		// only the asymmetric algorithms are accepted, the client secret is not a signing key
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %s", t.Header["alg"])
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return p.getKey(kid)
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, ErrInvalidIdToken
+	}
+
+	now := time.Now().Unix()
+
+	// checking the standard claims
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(doc.Issuer, "/") {
+		return Claims{}, ErrInvalidIdToken
+	}
+	if !claims.VerifyAudience(p.ClientId, true) || !claims.VerifyExpiresAt(now, true) {
+		return Claims{}, ErrInvalidIdToken
+	}
+
+	// if there are more audiences, the token has to be issued to this client
+	if azp, ok := claims["azp"].(string); ok && azp != p.ClientId {
+		return Claims{}, ErrInvalidIdToken
+	}
+
+	// the nonce protects against replaying an id token of another login
+	if claimed, _ := claims["nonce"].(string); claimed != nonce {
+		return Claims{}, ErrInvalidIdToken
+	}
+
+	result := Claims{}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+
+	// some providers send the email_verified claim as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = verified
+	case string:
+		result.EmailVerified = verified == "true"
+	}
+
+	if result.Subject == "" {
+		return Claims{}, ErrInvalidIdToken
+	}
+
+	return result, nil
+}
@@ -0,0 +1,216 @@
+// Package oidctest runs a local OpenID Connect provider for the tests of the logins,
+// the same way the httptest package runs a local http server.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// the key id of the signing key of the issuer
+const Kid = "test-key"
+
+// a local OpenID Connect provider
+// it logs in User at the authorization endpoint without asking anything,
+// and returns the id token of the code at the token endpoint if the PKCE verifier matches
+type Issuer struct {
+	URL          string
+	ClientId     string
+	ClientSecret string
+
+	// the claims of the user logging in, the standard claims are added to them
+	User jwt.MapClaims
+
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	lock         sync.Mutex
+	codes        map[string]authorization
+	jwksRequests int
+}
+
+// a code issued by the authorization endpoint
+type authorization struct {
+	Challenge string
+	Claims    jwt.MapClaims
+}
+
+// starts a new issuer, it has to be closed at the end of the test
+func NewIssuer(clientId string, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := &Issuer{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		User:         jwt.MapClaims{},
+		key:          key,
+		codes:        map[string]authorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/authorize", issuer.authorize)
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	issuer.URL = issuer.server.URL
+
+	return issuer, nil
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// returns how many times the keys have been fetched
+func (i *Issuer) JwksRequests() int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.jwksRequests
+}
+
+// returns the standard claims of an id token issued to the client now
+func (i *Issuer) Claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   i.ClientId,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": nonce,
+	}
+}
+
+// signs the claims with the key of the issuer, like the id tokens
+func (i *Issuer) Sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = Kid
+
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		panic(err)
+	}
+
+	return signed
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	i.lock.Lock()
+	i.jwksRequests++
+	i.lock.Unlock()
+
+	public := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": Kid,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+// logs in the user and redirects back to the client with a code
+func (i *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != i.ClientId || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("redirect_uri") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	claims := i.Claims(query.Get("nonce"))
+	for key, value := range i.User {
+		claims[key] = value
+	}
+
+	code := randomString()
+
+	i.lock.Lock()
+	i.codes[code] = authorization{Challenge: query.Get("code_challenge"), Claims: claims}
+	i.lock.Unlock()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// exchanges a code for the id token, the codes can only be used once
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, _ := r.BasicAuth()
+	clientId, _ := url.QueryUnescape(id)
+	clientSecret, _ := url.QueryUnescape(secret)
+	if r.Method != http.MethodPost || clientId != i.ClientId || clientSecret != i.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	i.lock.Lock()
+	auth, ok := i.codes[r.PostFormValue("code")]
+	delete(i.codes, r.PostFormValue("code"))
+	i.lock.Unlock()
+
+	// the verifier has to match the challenge of the authorization request
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.Challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"id_token":     i.Sign(auth.Claims),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
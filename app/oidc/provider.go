@@ -0,0 +1,266 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	ErrUnknownKey      = errors.New("unknown signing key")
+)
+
+// the http client of the requests to the providers
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// the keys are fetched again at most this often, so the tokens with unknown key ids
+// can't be used to make the api send requests to the provider
+var keyRefreshInterval = time.Minute
+
+// an OpenID Connect provider, configured from the environment variables
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+
+	lock      sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// the parts of the discovery document that are used
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// a key of the json web key set
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+var providers = map[string]*Provider{}
+
+func Setup() error {
+	// OIDC_PROVIDERS is a comma separated list of the names of the providers
+	// every provider is configured with the OIDC_<NAME>_* variables
+	providers = map[string]*Provider{}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := &Provider{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientId:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectUrl:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+
+		if provider.Issuer == "" || provider.ClientId == "" {
+			return fmt.Errorf("%sISSUER and %sCLIENT_ID are required", prefix, prefix)
+		}
+
+		if provider.RedirectUrl == "" {
+			provider.RedirectUrl = backend() + "/api/v1/oidc/" + name + "/callback"
+		}
+
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+
+		providers[name] = provider
+	}
+
+	return nil
+}
+
+// returns the names of the configured providers
+func Providers() []string {
+	names := []string{}
+	for name := range providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func GetProvider(name string) (*Provider, error) {
+	provider, ok := providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return provider, nil
+}
+
+// returns the discovery document of the provider
+// it's only fetched on the first login, so the app can start while the provider is down
+func (p *Provider) getDiscovery() (*discovery, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discovery
+	if err := getJSON(p.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+
+	// the issuer of the document has to be the configured one
+	if strings.TrimSuffix(doc.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("the issuer of the discovery document is %s", doc.Issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JwksUri == "" {
+		return nil, errors.New("the discovery document is incomplete")
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// returns the public key with the given id
+// the keys are fetched again if the id is unknown, since the provider can rotate its keys,
+// but only once in a keyRefreshInterval
+func (p *Provider) getKey(kid string) (interface{}, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	if !p.fetchedAt.IsZero() && time.Since(p.fetchedAt) < keyRefreshInterval {
+		return nil, ErrUnknownKey
+	}
+	p.fetchedAt = time.Now()
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(doc.JwksUri, &set); err != nil {
+		return nil, err
+	}
+
+	p.keys = map[string]interface{}{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		if key, err := k.publicKey(); err == nil {
+			p.keys[k.Kid] = key
+		}
+	}
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func getJSON(url string, v interface{}) error {
+	res, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// returns the public url of the api, used in the redirect urls
+func backend() string {
+	if url := os.Getenv("BACKEND"); url != "" {
+		return url
+	}
+
+	return "http://localhost:" + os.Getenv("PORT")
+}
@@ -0,0 +1,207 @@
+package oidc
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/oidc/oidctest"
+	"github.com/golang-jwt/jwt"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	t.Helper()
+
+	issuer, err := oidctest.NewIssuer("todo-client", "secret:with&chars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(issuer.Close)
+
+	provider := &Provider{
+		Name:         "test",
+		Issuer:       issuer.URL,
+		ClientId:     issuer.ClientId,
+		ClientSecret: issuer.ClientSecret,
+		RedirectUrl:  "http://localhost/api/v1/oidc/test/callback",
+		Scopes:       []string{"openid", "email"},
+	}
+
+	return provider, issuer
+}
+
+// logs in at the issuer and returns the code of the callback
+func authorize(t *testing.T, provider *Provider, flow Flow) string {
+	t.Helper()
+
+	authUrl, err := provider.AuthUrl(flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := noRedirect.Get(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil || res.StatusCode != http.StatusFound {
+		t.Fatalf("authorization failed: %d %s", res.StatusCode, res.Header.Get("Location"))
+	}
+
+	if callback.Query().Get("state") != flow.State {
+		t.Fatalf("state = %q, want %q", callback.Query().Get("state"), flow.State)
+	}
+
+	return callback.Query().Get("code")
+}
+
+func TestLogin(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	issuer.User = jwt.MapClaims{"sub": "42", "email": "john@example.com", "email_verified": "true", "name": "John"}
+
+	flow, err := NewFlow(provider.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := authorize(t, provider, flow)
+
+	// the code only works with the verifier of the login
+	if _, err := provider.Exchange(code, "wrong verifier"); err == nil {
+		t.Fatal("the code was exchanged with a wrong verifier")
+	}
+
+	code = authorize(t, provider, flow)
+	idToken, err := provider.Exchange(code, flow.Verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the codes can only be used once
+	if _, err := provider.Exchange(code, flow.Verifier); err == nil {
+		t.Fatal("the code was exchanged twice")
+	}
+
+	claims, err := provider.Verify(idToken, flow.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Claims{Subject: "42", Email: "john@example.com", EmailVerified: true, Name: "John"}
+	if claims != want {
+		t.Fatalf("claims = %+v, want %+v", claims, want)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+
+	valid := func() jwt.MapClaims {
+		claims := issuer.Claims("nonce")
+		claims["sub"] = "42"
+		return claims
+	}
+
+	with := func(key string, value interface{}) string {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+
+		return issuer.Sign(claims)
+	}
+
+	// signed with the client secret, which the client knows too
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid()).SignedString([]byte(provider.ClientSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not signed at all
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signature of another token
+	signature := issuer.Sign(valid())
+	payload := with("sub", "1")
+	tampered := payload[:strings.LastIndex(payload, ".")] + signature[strings.LastIndex(signature, "."):]
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"wrong audience", with("aud", "another-client"), "nonce"},
+		{"wrong issuer", with("iss", "https://evil.example.com"), "nonce"},
+		{"wrong nonce", issuer.Sign(valid()), "another nonce"},
+		{"no nonce", with("nonce", nil), "nonce"},
+		{"expired", with("exp", time.Now().Add(-time.Minute).Unix()), "nonce"},
+		{"authorized party of another client", with("azp", "another-client"), "nonce"},
+		{"no subject", with("sub", nil), "nonce"},
+		{"HS256", hmac, "nonce"},
+		{"alg none", none, "nonce"},
+		{"tampered", tampered, "nonce"},
+	}
+
+	if _, err := provider.Verify(issuer.Sign(valid()), "nonce"); err != nil {
+		t.Fatalf("the valid token was rejected: %s", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := provider.Verify(test.token, test.nonce); err == nil {
+				t.Fatal("the token was accepted")
+			}
+		})
+	}
+}
+
+func TestUnknownKeysAreFetchedOnlyOnceInAnInterval(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+
+	unknown := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.Claims("nonce"))
+		token.Header["kid"] = kid
+		signed, _ := token.SigningString()
+		return signed + ".c2lnbmF0dXJl"
+	}
+
+	if _, err := provider.Verify(unknown("first"), "nonce"); err == nil {
+		t.Fatal("the token with an unknown key was accepted")
+	}
+	if _, err := provider.Verify(unknown("second"), "nonce"); err == nil {
+		t.Fatal("the token with an unknown key was accepted")
+	}
+
+	// the known keys are still accepted without fetching them again
+	claims := issuer.Claims("nonce")
+	claims["sub"] = "42"
+	if _, err := provider.Verify(issuer.Sign(claims), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests := issuer.JwksRequests(); requests != 1 {
+		t.Fatalf("the keys were fetched %d times, want 1", requests)
+	}
+
+	// after the interval the keys are fetched again, so the rotated keys are found
+	provider.lock.Lock()
+	provider.fetchedAt = time.Now().Add(-keyRefreshInterval)
+	provider.lock.Unlock()
+
+	provider.Verify(unknown("third"), "nonce")
+	if requests := issuer.JwksRequests(); requests != 2 {
+		t.Fatalf("the keys were fetched %d times, want 2", requests)
+	}
+}
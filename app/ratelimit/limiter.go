@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// a token bucket, which allows Burst requests at once and refills Rate requests every second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+func PerHour(n int) Limit {
+	return Limit{Rate: float64(n) / 3600, Burst: n}
+}
+
+// a progressive lockout: after Threshold failures the key is locked for Base,
+// which is doubled with every further failure up to Max
+// the failures are forgotten after Window without a new one
+type Lockout struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+}
+
+// returns how long the key is locked after the given number of failures
+func (l Lockout) Duration(failures int) time.Duration {
+	if failures < l.Threshold {
+		return 0
+	}
+
+	duration := l.Base
+	for i := l.Threshold; i < failures && duration < l.Max; i++ {
+		duration *= 2
+	}
+
+	if duration > l.Max {
+		return l.Max
+	}
+
+	return duration
+}
+
+// a Store keeps the buckets and the lockouts
+// the memory store is the default, a shared store is needed if there are more instances of the api
+type Store interface {
+	// takes a token from the bucket of the key, and returns how long to wait if it's empty
+	Take(key string, limit Limit, now time.Time) (time.Duration, error)
+
+	// counts a failure of the key, and returns how long it's locked
+	Fail(key string, lockout Lockout, now time.Time) (time.Duration, error)
+
+	// returns how long the key is locked
+	LockedFor(key string, now time.Time) (time.Duration, error)
+
+	// forgets the failures of the key
+	Reset(key string) error
+}
+
+var Backend Store = NewMemoryStore()
+
+func Setup(store Store) {
+	Backend = store
+}
+
+// refills the bucket since the last update and takes a token from it
+// returns the tokens left, and how long to wait if there was no token
+func TakeToken(tokens float64, updated time.Time, limit Limit, now time.Time) (float64, time.Duration) {
+	tokens = math.Min(float64(limit.Burst), tokens+now.Sub(updated).Seconds()*limit.Rate)
+
+	if tokens < 1 {
+		wait := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+		return tokens, wait
+	}
+
+	return tokens - 1, 0
+}
+
+// returns how long the key is still locked
+func remaining(lockedUntil time.Time, now time.Time) time.Duration {
+	if !lockedUntil.After(now) {
+		return 0
+	}
+
+	return lockedUntil.Sub(now)
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTakeToken(t *testing.T) {
+	now := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	limit := PerMinute(6)
+
+	tests := []struct {
+		name       string
+		tokens     float64
+		elapsed    time.Duration
+		wantTokens float64
+		wantWait   time.Duration
+	}{
+		{"a full bucket", 6, 0, 5, 0},
+		{"the last token", 1, 0, 0, 0},
+		{"an empty bucket", 0, 0, 0, 10 * time.Second},
+		{"a partly refilled bucket", 0, 4 * time.Second, 0.4, 6 * time.Second},
+		{"a refilled token", 0, 10 * time.Second, 0, 0},
+		{"the bucket isn't refilled over the burst", 2, time.Hour, 5, 0},
+	}
+
+	for _, test := range tests {
+		tokens, wait := TakeToken(test.tokens, now.Add(-test.elapsed), limit, now)
+		if diff := tokens - test.wantTokens; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: tokens = %f, want %f", test.name, tokens, test.wantTokens)
+		}
+		if diff := wait - test.wantWait; diff > time.Millisecond || diff < -time.Millisecond {
+			t.Errorf("%s: wait = %s, want %s", test.name, wait, test.wantWait)
+		}
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	lockout := Lockout{Threshold: 3, Base: time.Minute, Max: 10 * time.Minute, Window: time.Hour}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 8 * time.Minute},
+		{7, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, test := range tests {
+		if got := lockout.Duration(test.failures); got != test.want {
+			t.Errorf("after %d failures: locked for %s, want %s", test.failures, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// how long an unused key is kept in the memory
+const memoryTTL = 24 * time.Hour
+
+// keeps the buckets and the lockouts in the memory of the process
+type MemoryStore struct {
+	lock      sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+type entry struct {
+	tokens      float64
+	updated     time.Time
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*entry{}}
+}
+
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (time.Duration, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{tokens: float64(limit.Burst), updated: now}
+		s.entries[key] = e
+	}
+
+	var wait time.Duration
+	e.tokens, wait = TakeToken(e.tokens, e.updated, limit, now)
+	e.updated = now
+
+	s.sweep(now)
+	return wait, nil
+}
+
+func (s *MemoryStore) Fail(key string, lockout Lockout, now time.Time) (time.Duration, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{updated: now}
+		s.entries[key] = e
+	}
+
+	// the old failures are forgotten
+	if now.Sub(e.lastFailure) > lockout.Window {
+		e.failures = 0
+	}
+
+	e.failures++
+	e.lastFailure = now
+
+	if duration := lockout.Duration(e.failures); duration > 0 {
+		e.lockedUntil = now.Add(duration)
+	}
+
+	return remaining(e.lockedUntil, now), nil
+}
+
+func (s *MemoryStore) LockedFor(key string, now time.Time) (time.Duration, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return 0, nil
+	}
+
+	return remaining(e.lockedUntil, now), nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.failures = 0
+		e.lockedUntil = time.Time{}
+	}
+
+	return nil
+}
+
+// deletes the unused keys, at most once every minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.Sub(e.updated) > memoryTTL && now.Sub(e.lastFailure) > memoryTTL && !e.lockedUntil.After(now) {
+			delete(s.entries, key)
+		}
+	}
+}
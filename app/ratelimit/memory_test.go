@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	limit := PerMinute(3)
+
+	// the burst is allowed at once, then the requests have to wait for the refill
+	for i := 0; i < 3; i++ {
+		if wait, _ := s.Take("login", limit, now); wait != 0 {
+			t.Fatalf("request %d: wait = %s, want 0", i+1, wait)
+		}
+	}
+	if wait, _ := s.Take("login", limit, now); wait != 20*time.Second {
+		t.Errorf("wait = %s, want 20s", wait)
+	}
+
+	// the keys have their own buckets
+	if wait, _ := s.Take("register", limit, now); wait != 0 {
+		t.Errorf("another key: wait = %s, want 0", wait)
+	}
+
+	if wait, _ := s.Take("login", limit, now.Add(20*time.Second)); wait != 0 {
+		t.Errorf("after the refill: wait = %s, want 0", wait)
+	}
+}
+
+func TestMemoryStoreLockout(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	lockout := Lockout{Threshold: 3, Base: time.Minute, Max: time.Hour, Window: 15 * time.Minute}
+
+	for i := 1; i <= 2; i++ {
+		if locked, _ := s.Fail("john", lockout, now); locked != 0 {
+			t.Fatalf("failure %d: locked for %s, want 0", i, locked)
+		}
+	}
+
+	if locked, _ := s.Fail("john", lockout, now); locked != time.Minute {
+		t.Errorf("locked for %s, want 1m", locked)
+	}
+	if locked, _ := s.LockedFor("john", now.Add(20*time.Second)); locked != 40*time.Second {
+		t.Errorf("locked for %s after 20s, want 40s", locked)
+	}
+	if locked, _ := s.LockedFor("john", now.Add(time.Minute)); locked != 0 {
+		t.Errorf("locked for %s after the lockout, want 0", locked)
+	}
+	if locked, _ := s.LockedFor("jane", now); locked != 0 {
+		t.Errorf("another key is locked for %s", locked)
+	}
+
+	// the next failure in the window doubles the lockout
+	if locked, _ := s.Fail("john", lockout, now.Add(time.Minute)); locked != 2*time.Minute {
+		t.Errorf("locked for %s, want 2m", locked)
+	}
+
+	// the failures are forgotten after the window
+	if locked, _ := s.Fail("john", lockout, now.Add(20*time.Minute)); locked != 0 {
+		t.Errorf("locked for %s after the window, want 0", locked)
+	}
+
+	// and after a reset
+	s.Fail("jane", lockout, now)
+	s.Fail("jane", lockout, now)
+	s.Fail("jane", lockout, now)
+	s.Reset("jane")
+	if locked, _ := s.LockedFor("jane", now); locked != 0 {
+		t.Errorf("locked for %s after the reset, want 0", locked)
+	}
+	if locked, _ := s.Fail("jane", lockout, now); locked != 0 {
+		t.Errorf("locked for %s after a failure following the reset, want 0", locked)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	limit := PerMinute(3)
+	lockout := Lockout{Threshold: 1, Base: 48 * time.Hour, Max: 48 * time.Hour, Window: time.Hour}
+
+	s.Take("old", limit, now)
+	s.Fail("locked", lockout, now)
+	s.Take("recent", limit, now.Add(12*time.Hour))
+
+	// the unused keys are deleted, but not the locked or the recently used ones
+	s.Take("new", limit, now.Add(25*time.Hour))
+
+	for key, want := range map[string]bool{"old": false, "locked": true, "recent": true, "new": true} {
+		if _, ok := s.entries[key]; ok != want {
+			t.Errorf("%s is kept: %v, want %v", key, ok, want)
+		}
+	}
+
+	// the keys aren't swept again within a minute
+	s.Take("stale", limit, now)
+	s.Take("new", limit, now.Add(25*time.Hour+30*time.Second))
+	if _, ok := s.entries["stale"]; !ok {
+		t.Fatal("the keys were swept again within a minute")
+	}
+	s.Take("new", limit, now.Add(25*time.Hour+time.Minute))
+	if _, ok := s.entries["stale"]; ok {
+		t.Error("the unused key was kept after the next sweep")
+	}
+}
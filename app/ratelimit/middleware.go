@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0l1v3rr/todo/app/util"
+	"github.com/gin-gonic/gin"
+)
+
+// limits the requests of every ip address to the endpoint
+// if the store fails, the request is allowed
+func PerIp(name string, limit Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wait, err := Backend.Take(name+":ip:"+c.ClientIP(), limit, time.Now())
+		if err != nil {
+			log.Println("Failed to check the rate limit: " + err.Error())
+		}
+
+		if wait > 0 {
+			Reject(c, wait, "Too many requests. Please try again later.")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// aborts the request with 429 and the Retry-After header
+func Reject(c *gin.Context, wait time.Duration, msg string) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, util.Error{Message: msg})
+}
@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPerIp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	Setup(NewMemoryStore())
+	t.Cleanup(func() { Setup(NewMemoryStore()) })
+
+	r := gin.New()
+	r.POST("/login", PerIp("login", PerMinute(2)), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := request("10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, w.Code)
+		}
+	}
+
+	w := request("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Errorf("status = %d with Retry-After %q, want 429 with 30", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// the other addresses have their own limit
+	if w := request("10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("another ip: status = %d, want 200", w.Code)
+	}
+}
@@ -1,6 +1,12 @@
 package util
 
-import "math/rand"
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/rand"
+)
 
 const chars string = "abcdefghijklmnopqrstuvwxyz0123456789"
 
@@ -13,3 +19,19 @@ func GenerateHash(length int) string {
 
 	return res
 }
+
+// generates a url-safe random token with the crypto/rand package
+func GenerateToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := crand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashes a token, so only the hash has to be stored in the db
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
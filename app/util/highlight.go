@@ -0,0 +1,86 @@
+package util
+
+import (
+	"html"
+	"strings"
+)
+
+// returns the part of the text around the first match of the terms, with
+// every match wrapped in <mark> tags, the rest of the text is html escaped
+func Highlight(text string, terms []string, radius int) string {
+	lower := strings.ToLower(text)
+
+	// the matching relies on the byte offsets being the same
+	if len(lower) != len(text) {
+		lower = text
+	}
+
+	// finding the first match to center the snippet on
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, strings.ToLower(term)); i != -1 && (first == -1 || i < first) {
+			first = i
+		}
+	}
+
+	// cutting the snippet out of the text
+	start, end := 0, len(text)
+	if first > radius {
+		start = first - radius
+	}
+	if first != -1 && first+radius < end {
+		end = first + radius
+	} else if first == -1 && 2*radius < end {
+		end = 2 * radius
+	}
+
+	// not cutting the runes in half
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+
+	// wrapping the matches with the mark tags
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	snippet, lowerSnippet := text[start:end], lower[start:end]
+	for i := 0; i < len(snippet); {
+		length := matchLength(lowerSnippet[i:], terms)
+		if length == 0 {
+			b.WriteString(html.EscapeString(snippet[i : i+1]))
+			i++
+			continue
+		}
+
+		b.WriteString("<mark>" + html.EscapeString(snippet[i:i+length]) + "</mark>")
+		i += length
+	}
+
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// returns the length of the longest term the text starts with
+func matchLength(text string, terms []string) int {
+	length := 0
+
+	for _, term := range terms {
+		if len(term) > length && strings.HasPrefix(text, strings.ToLower(term)) {
+			length = len(term)
+		}
+	}
+
+	return length
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,286 @@
+package util
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the frequencies a recurrence rule can have
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// the codes of the weekdays in the rules, indexed by the weekday
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// the formats of UNTIL, a date or a time in UTC
+const (
+	untilDateFormat = "20060102"
+	untilTimeFormat = "20060102T150405Z"
+)
+
+// a parsed RRULE-style recurrence rule, for example:
+//
+//	FREQ=DAILY;INTERVAL=2
+//	FREQ=WEEKLY;BYDAY=MO,WE,FR
+//	FREQ=MONTHLY;BYMONTHDAY=15
+//	FREQ=DAILY;INTERVAL=3;FROM=COMPLETION
+//	FREQ=WEEKLY;BYDAY=TU;COUNT=10
+//	FREQ=MONTHLY;UNTIL=20221231
+type Recurrence struct {
+	Freq           string
+	Interval       int
+	ByDay          []time.Weekday
+	ByMonthDay     int
+	FromCompletion bool
+
+	// the number of occurrences including the first one, 0 means no limit
+	Count int
+
+	// the last possible occurrence, only its date counts if UntilDate is set
+	Until     time.Time
+	UntilDate bool
+}
+
+func ParseRecurrence(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+
+	// parsing the KEY=VALUE parts of the rule
+	for _, part := range strings.Split(strings.ToUpper(rule), ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return Recurrence{}, errors.New("every part of the rule has to be KEY=VALUE")
+		}
+
+		switch key {
+		case "FREQ":
+			if value != FreqDaily && value != FreqWeekly && value != FreqMonthly {
+				return Recurrence{}, errors.New("FREQ has to be DAILY, WEEKLY or MONTHLY")
+			}
+			r.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > 365 {
+				return Recurrence{}, errors.New("INTERVAL has to be between 1 and 365")
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return Recurrence{}, errors.New("BYDAY has to be a list of MO, TU, WE, TH, FR, SA, SU")
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day < 1 || day > 31 {
+				return Recurrence{}, errors.New("BYMONTHDAY has to be between 1 and 31")
+			}
+			r.ByMonthDay = day
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 || count > 1000 {
+				return Recurrence{}, errors.New("COUNT has to be between 1 and 1000")
+			}
+			r.Count = count
+		case "UNTIL":
+			until, err := time.Parse(untilTimeFormat, value)
+			if err != nil {
+				until, err = time.Parse(untilDateFormat, value)
+				r.UntilDate = true
+			}
+			if err != nil {
+				return Recurrence{}, errors.New("UNTIL has to be a date like 20221231 or a UTC time like 20221231T170000Z")
+			}
+			r.Until = until
+		case "FROM":
+			if value != "COMPLETION" {
+				return Recurrence{}, errors.New("FROM can only be COMPLETION")
+			}
+			r.FromCompletion = true
+		default:
+			return Recurrence{}, errors.New("unknown rule part: " + key)
+		}
+	}
+
+	// checking that the parts fit together
+	if r.Freq == "" {
+		return Recurrence{}, errors.New("FREQ is required")
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqWeekly {
+		return Recurrence{}, errors.New("BYDAY can only be used with FREQ=WEEKLY")
+	}
+	if r.ByMonthDay != 0 && r.Freq != FreqMonthly {
+		return Recurrence{}, errors.New("BYMONTHDAY can only be used with FREQ=MONTHLY")
+	}
+	if r.FromCompletion && (len(r.ByDay) > 0 || r.ByMonthDay != 0) {
+		return Recurrence{}, errors.New("FROM=COMPLETION can not be used with BYDAY or BYMONTHDAY")
+	}
+	if r.Count != 0 && !r.Until.IsZero() {
+		return Recurrence{}, errors.New("COUNT and UNTIL can not be used together")
+	}
+
+	return r, nil
+}
+
+// returns the first occurrence after the given time,
+// keeping the time of the day of t, the weekdays and the days are in the location of t
+func (r Recurrence) Next(t time.Time) time.Time {
+	switch r.Freq {
+	case FreqWeekly:
+		// without weekdays it simply repeats every interval weeks
+		if len(r.ByDay) == 0 {
+			return t.AddDate(0, 0, 7*r.Interval)
+		}
+
+		// looking for the next matching weekday, skipping the
+		// weeks that are not part of the interval
+		week := StartOfWeek(t, t.Location())
+		for i := 1; ; i++ {
+			next := t.AddDate(0, 0, i)
+			weeks := int(StartOfWeek(next, t.Location()).Sub(week).Hours()+12) / (24 * 7)
+			if weeks%r.Interval == 0 && r.hasWeekday(next.Weekday()) {
+				return next
+			}
+		}
+	case FreqMonthly:
+		// without a day of the month it keeps the day of t
+		day := r.ByMonthDay
+		if day == 0 {
+			day = t.Day()
+		}
+
+		// the first month is only used if the day is still ahead
+		for i := 0; ; i += r.Interval {
+			first := time.Date(t.Year(), t.Month()+time.Month(i), 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+
+			// the months that are too short get their last day
+			monthDay := day
+			if last := daysIn(first); monthDay > last {
+				monthDay = last
+			}
+
+			next := first.AddDate(0, 0, monthDay-1)
+			if next.After(t) {
+				return next
+			}
+		}
+	default:
+		return t.AddDate(0, 0, r.Interval)
+	}
+}
+
+// returns the rule with the day of the month of t pinned if it repeats monthly without one,
+// so the short months don't shift the later occurrences, for example from the 31st to the 28th
+// the rules repeating from the completion are not pinned, they always start from the completion
+func (r Recurrence) Pinned(t time.Time) Recurrence {
+	if r.Freq == FreqMonthly && r.ByMonthDay == 0 && !r.FromCompletion {
+		r.ByMonthDay = t.Day()
+	}
+
+	return r
+}
+
+// returns whether the time is after the end of the rule set by UNTIL
+func (r Recurrence) Ended(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+
+	// the date of UNTIL is compared to the date of t in its own location
+	if r.UntilDate {
+		year, month, day := t.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+
+	return t.After(r.Until)
+}
+
+// returns the next count occurrences after the given time, or less if the rule ends sooner
+// the given time is the first occurrence, so COUNT includes it
+func (r Recurrence) Upcoming(t time.Time, count int) []time.Time {
+	occurrences := []time.Time{}
+
+	if r.Count != 0 && count > r.Count-1 {
+		count = r.Count - 1
+	}
+
+	r = r.Pinned(t)
+	for i := 0; i < count; i++ {
+		t = r.Next(t)
+		if r.Ended(t) {
+			break
+		}
+
+		occurrences = append(occurrences, t)
+	}
+
+	return occurrences
+}
+
+// returns the rule in the same format it's parsed from
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := []string{}
+		for _, day := range r.ByDay {
+			days = append(days, weekdayCodes[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+
+	if r.Count != 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.UntilDate {
+		parts = append(parts, "UNTIL="+r.Until.Format(untilDateFormat))
+	} else if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilTimeFormat))
+	}
+
+	if r.FromCompletion {
+		parts = append(parts, "FROM=COMPLETION")
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func (r Recurrence) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+func daysIn(t time.Time) int {
+	// the day before the first day of the next month
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
@@ -0,0 +1,185 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule string
+		want Recurrence
+	}{
+		{"FREQ=DAILY", Recurrence{Freq: FreqDaily, Interval: 1}},
+		{"freq=daily; interval=2", Recurrence{Freq: FreqDaily, Interval: 2}},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", Recurrence{Freq: FreqWeekly, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Friday}}},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", Recurrence{Freq: FreqMonthly, Interval: 1, ByMonthDay: 31}},
+		{"FREQ=DAILY;INTERVAL=3;FROM=COMPLETION", Recurrence{Freq: FreqDaily, Interval: 3, FromCompletion: true}},
+		{"FREQ=WEEKLY;COUNT=10", Recurrence{Freq: FreqWeekly, Interval: 1, Count: 10}},
+		{"FREQ=DAILY;UNTIL=20221231", Recurrence{Freq: FreqDaily, Interval: 1, Until: date(2022, 12, 31, 0), UntilDate: true}},
+		{"FREQ=DAILY;UNTIL=20221231T170000Z", Recurrence{Freq: FreqDaily, Interval: 1, Until: date(2022, 12, 31, 17)}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseRecurrence(test.rule)
+		if err != nil {
+			t.Errorf("%s: %s", test.rule, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s = %+v, want %+v", test.rule, got, test.want)
+		}
+	}
+}
+
+func TestParseInvalidRecurrence(t *testing.T) {
+	rules := []string{
+		"",
+		"DAILY",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=366",
+		"FREQ=DAILY;INTERVAL=two",
+		"FREQ=WEEKLY;BYDAY=MO,XX",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=MO;FROM=COMPLETION",
+		"FREQ=DAILY;FROM=START",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=1001",
+		"FREQ=DAILY;UNTIL=2022-12-31",
+		"FREQ=DAILY;COUNT=2;UNTIL=20221231",
+		"FREQ=DAILY;BYHOUR=8",
+	}
+
+	for _, rule := range rules {
+		if r, err := ParseRecurrence(rule); err == nil {
+			t.Errorf("%q was parsed as %+v, want an error", rule, r)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+
+	tests := []struct {
+		name string
+		rule string
+		from time.Time
+		want time.Time
+	}{
+		{"daily", "FREQ=DAILY", date(2022, 8, 1, 9), date(2022, 8, 2, 9)},
+		{"every 3 days", "FREQ=DAILY;INTERVAL=3", date(2022, 8, 30, 9), date(2022, 9, 2, 9)},
+		{"weekly", "FREQ=WEEKLY", date(2022, 8, 1, 9), date(2022, 8, 8, 9)},
+		{"every 2 weeks", "FREQ=WEEKLY;INTERVAL=2", date(2022, 8, 1, 9), date(2022, 8, 15, 9)},
+		{"the next weekday of the week", "FREQ=WEEKLY;BYDAY=MO,WE,FR", date(2022, 8, 1, 9), date(2022, 8, 3, 9)},
+		{"the first weekday of the next week", "FREQ=WEEKLY;BYDAY=MO,WE", date(2022, 8, 3, 9), date(2022, 8, 8, 9)},
+		{"the weekday after a skipped week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", date(2022, 8, 5, 9), date(2022, 8, 15, 9)},
+		{"monthly", "FREQ=MONTHLY", date(2022, 8, 15, 9), date(2022, 9, 15, 9)},
+		{"the day later this month", "FREQ=MONTHLY;BYMONTHDAY=20", date(2022, 8, 15, 9), date(2022, 8, 20, 9)},
+		{"the day in the next month", "FREQ=MONTHLY;BYMONTHDAY=10", date(2022, 8, 15, 9), date(2022, 9, 10, 9)},
+		{"the end of a short month", "FREQ=MONTHLY;BYMONTHDAY=31", date(2022, 1, 31, 9), date(2022, 2, 28, 9)},
+		{"the end of february in a leap year", "FREQ=MONTHLY;BYMONTHDAY=30", date(2024, 1, 30, 9), date(2024, 2, 29, 9)},
+		{"the 31st after a short month", "FREQ=MONTHLY;BYMONTHDAY=31", date(2022, 2, 28, 9), date(2022, 3, 31, 9)},
+		{"every 3 months", "FREQ=MONTHLY;INTERVAL=3", date(2022, 11, 5, 9), date(2023, 2, 5, 9)},
+		{"the same local time after the DST change", "FREQ=DAILY", time.Date(2022, 10, 29, 9, 0, 0, 0, budapest), time.Date(2022, 10, 30, 9, 0, 0, 0, budapest)},
+		{"the weekday in the local time zone", "FREQ=WEEKLY;BYDAY=TU", time.Date(2022, 8, 1, 23, 30, 0, 0, budapest), time.Date(2022, 8, 2, 23, 30, 0, 0, budapest)},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRecurrence(test.rule)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		if got := r.Next(test.from); !got.Equal(test.want) {
+			t.Errorf("%s: next after %s = %s, want %s", test.name, test.from, got, test.want)
+		}
+	}
+}
+
+func TestRecurrenceUpcoming(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		from  time.Time
+		count int
+		want  []time.Time
+	}{
+		{
+			"the monthly day is kept after a short month",
+			"FREQ=MONTHLY", date(2022, 1, 31, 9), 3,
+			[]time.Time{date(2022, 2, 28, 9), date(2022, 3, 31, 9), date(2022, 4, 30, 9)},
+		},
+		{
+			"COUNT includes the first occurrence",
+			"FREQ=DAILY;COUNT=3", date(2022, 8, 1, 9), 5,
+			[]time.Time{date(2022, 8, 2, 9), date(2022, 8, 3, 9)},
+		},
+		{
+			"a COUNT of 1 has no other occurrences",
+			"FREQ=DAILY;COUNT=1", date(2022, 8, 1, 9), 5,
+			[]time.Time{},
+		},
+		{
+			"the occurrences on the date of UNTIL are included",
+			"FREQ=DAILY;UNTIL=20220803", date(2022, 8, 1, 23), 5,
+			[]time.Time{date(2022, 8, 2, 23), date(2022, 8, 3, 23)},
+		},
+		{
+			"the occurrences after the time of UNTIL are not included",
+			"FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20220808T080000Z", date(2022, 8, 1, 9), 5,
+			[]time.Time{date(2022, 8, 4, 9)},
+		},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRecurrence(test.rule)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		if got := r.Upcoming(test.from, test.count); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: upcoming = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRecurrenceString(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"freq=weekly;byday=fr,mo;count=4", "FREQ=WEEKLY;BYDAY=FR,MO;COUNT=4"},
+		{"FREQ=MONTHLY;UNTIL=20221231;BYMONTHDAY=31", "FREQ=MONTHLY;BYMONTHDAY=31;UNTIL=20221231"},
+		{"FROM=COMPLETION;FREQ=DAILY;INTERVAL=3;UNTIL=20221231T170000Z", "FREQ=DAILY;INTERVAL=3;UNTIL=20221231T170000Z;FROM=COMPLETION"},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRecurrence(test.rule)
+		if err != nil {
+			t.Fatalf("%s: %s", test.rule, err)
+		}
+
+		got := r.String()
+		if got != test.want {
+			t.Errorf("%s = %s, want %s", test.rule, got, test.want)
+		}
+
+		// the formatted rule is parsed to the same rule
+		if again, err := ParseRecurrence(got); err != nil || !reflect.DeepEqual(again, r) {
+			t.Errorf("%s was parsed as %+v, %v, want %+v", got, again, err, r)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package util
+
+import "time"
+
+func LoadLocation(name string) (*time.Location, error) {
+	// if no time zone was specified, UTC is used
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	// converting the time to the given time zone and truncating it to midnight
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func StartOfWeek(t time.Time, loc *time.Location) time.Time {
+	// the week starts on monday
+	day := StartOfDay(t, loc)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func ToUTC(t *time.Time) *time.Time {
+	// nil means that the time is not set
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+	return &utc
+}
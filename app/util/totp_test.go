@@ -0,0 +1,108 @@
+package util
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// the secret of the test vectors of RFC 6238, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCode(t *testing.T) {
+	// the SHA1 vectors of RFC 6238, with the last 6 of their 8 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := TotpCode(rfcSecret, time.Unix(test.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != test.code {
+			t.Errorf("code at %d = %s, want %s", test.unix, code, test.code)
+		}
+	}
+
+	if _, err := TotpCode("not base32!", time.Now()); err == nil {
+		t.Error("an invalid secret gave a code")
+	}
+}
+
+func TestValidateTotp(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+		step   int64
+	}{
+		{"the current code", rfcSecret, "050471", true, step},
+		{"a lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", true, step},
+		{"the previous code", rfcSecret, "081804", true, step - 1},
+		{"a wrong code", rfcSecret, "123456", false, 0},
+		{"a code with 8 digits", rfcSecret, "07081804", false, 0},
+		{"an invalid secret", "not base32!", "050471", false, 0},
+	}
+
+	for _, test := range tests {
+		got, ok := ValidateTotp(test.secret, test.code, now)
+		if ok != test.ok || got != test.step {
+			t.Errorf("%s: %d, %v, want %d, %v", test.name, got, ok, test.step, test.ok)
+		}
+	}
+}
+
+func TestValidateTotpWindow(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := TotpCode(rfcSecret, now)
+
+	// one step of clock drift is allowed in both directions
+	for _, drift := range []time.Duration{-30 * time.Second, 0, 30 * time.Second} {
+		if _, ok := ValidateTotp(rfcSecret, code, now.Add(drift)); !ok {
+			t.Errorf("the code was rejected with %s of drift", drift)
+		}
+	}
+
+	for _, drift := range []time.Duration{-90 * time.Second, 90 * time.Second} {
+		if _, ok := ValidateTotp(rfcSecret, code, now.Add(drift)); ok {
+			t.Errorf("the code was accepted with %s of drift", drift)
+		}
+	}
+}
+
+func TestGenerateTotpSecret(t *testing.T) {
+	secret, err := GenerateTotpSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the secret works with the codes and is 160 bits like RFC 4226 recommends
+	code, err := TotpCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ValidateTotp(secret, code, time.Now()); !ok || len(secret) != 32 {
+		t.Errorf("secret %q doesn't work", secret)
+	}
+
+	uri, err := url.Parse(TotpUri("ToDo", "john@example.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != "ToDo" {
+		t.Errorf("uri = %s", uri)
+	}
+}
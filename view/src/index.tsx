@@ -1,6 +1,20 @@
 import ReactDOM from 'react-dom/client';
 import './index.css';
 import App from './App';
+import axios, { AxiosError, AxiosRequestConfig } from 'axios';
+
+// the access token is short-lived, so it's refreshed once when a request is unauthorized
+axios.interceptors.response.use(undefined, async (error: AxiosError) => {
+    const config = error.config as AxiosRequestConfig & { isRetry?: boolean };
+
+    if (error.response?.status !== 401 || config.isRetry || config.url?.endsWith("/api/v1/refresh")) {
+        return Promise.reject(error);
+    }
+
+    config.isRetry = true;
+    await axios.post(`${process.env.REACT_APP_BACKEND_DOMAIN}/api/v1/refresh`, null, { withCredentials: true });
+    return axios(config);
+});
 
 const root = ReactDOM.createRoot(
     document.getElementById('root') as HTMLElement
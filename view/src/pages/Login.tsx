@@ -1,5 +1,5 @@
 import { FC, useState } from "react";
-import { VscKey, VscMail } from "react-icons/vsc";
+import { VscKey, VscMail, VscShield } from "react-icons/vsc";
 import { Link } from "react-router-dom";
 
 import axios, { AxiosError } from "axios";
@@ -30,6 +30,15 @@ const Login:FC = () => {
         errorMessage: "" 
     });
 
+    // the second step of the login, if the user has 2fa
+    // after an oidc login the token comes in the fragment of the url
+    const [mfaToken, setMfaToken] = useState(() => new URLSearchParams(window.location.hash.slice(1)).get("mfaToken") || "");
+    const [code, setCode] = useState("");
+    const codeSettings: Settings = {
+        isError: false,
+        errorMessage: ""
+    };
+
     const checkForActiveButton = (): void => {
         if(passwordSettings.errorMessage === "" && emailSettings.errorMessage === "") {
             if(password.trim() == "" || email.trim() == "") {
@@ -127,6 +136,11 @@ const Login:FC = () => {
             if(res.status === 200) {
                 window.location.reload();
             }
+
+            // the login has to be finished with a code
+            if(res.status === 202) {
+                setMfaToken(res.data.mfaToken);
+            }
         })
         .catch(err => {
             const error = err as AxiosError;
@@ -134,6 +148,23 @@ const Login:FC = () => {
         });
     };
 
+    // handle 2fa code submit function
+    const handleCodeSubmit = (): void => {
+        setRequestError("");
+
+        axios.post(`${process.env.REACT_APP_BACKEND_DOMAIN}/api/v1/login/2fa`, {
+            mfaToken: mfaToken,
+            code: code.trim()
+        }, {
+            withCredentials: true
+        })
+        .then(() => window.location.reload())
+        .catch(err => {
+            const error = err as AxiosError;
+            setRequestError((error.response?.data as any).error);
+        });
+    };
+
     return (
         <div className="shadow-md bg-slate-100 border border-solid border-slate-200 rounded-md">
             <div className="text-center border-b border-b-slate-200 border-solid font-bold px-10 
@@ -146,6 +177,24 @@ const Login:FC = () => {
                     {requestError}
                 </div>}
 
+            {mfaToken !== "" ? <div className="flex items-center justify-center px-5 py-3 flex-col gap-6">
+                <InputField 
+                    setValue={setCode} 
+                    value={code}
+                    label="The code of your authenticator app or a recovery code:" 
+                    type="text" 
+                    placeholder="123456"
+                    icon={VscShield}
+                    settings={codeSettings}
+                    validate={() => codeSettings}
+                />
+
+                <ButtonPrimary 
+                    isActive={code.trim() !== ""} 
+                    onClick={handleCodeSubmit} 
+                    text="Verify" 
+                />
+            </div> :
             <div className="flex items-center justify-center px-5 py-3 flex-col gap-6">
                 <InputField 
                     setValue={setEmail} 
@@ -181,7 +230,7 @@ const Login:FC = () => {
                     onClick={handleFormSubmit} 
                     text="Log In" 
                 />
-            </div>
+            </div>}
         </div>
     );
 }